@@ -0,0 +1,99 @@
+package zen
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Result holds outcome of an asynchronous evaluation.
+type Result struct {
+	Response *EvaluationResponse
+	Err      error
+}
+
+// Request is a single evaluation performed by EvaluateAll.
+type Request struct {
+	Key     string
+	Input   any
+	Options EvaluationOptions
+}
+
+// EvaluateAllOptions configures EvaluateAll.
+type EvaluateAllOptions struct {
+	// Concurrency bounds the number of requests evaluated at once, defaults to GOMAXPROCS.
+	Concurrency int
+	// FailFast stops starting new requests after the first failure and returns its error.
+	// Requests which were not started are reported with context.Canceled.
+	FailFast bool
+}
+
+// EvaluateAsync evaluates key in the background, the returned channel receives exactly one Result and is closed.
+// Once ctx is done the Result carries ctx.Err(), native evaluation which already started runs to completion however.
+func EvaluateAsync(ctx context.Context, engine Engine, key string, input any, options EvaluationOptions) <-chan Result {
+	results := make(chan Result, 1)
+
+	go func() {
+		defer close(results)
+
+		if err := ctx.Err(); err != nil {
+			results <- Result{Err: err}
+			return
+		}
+
+		done := make(chan Result, 1)
+		go func() {
+			response, err := engine.EvaluateWithOpts(key, input, options)
+			done <- Result{Response: response, Err: err}
+		}()
+
+		select {
+		case result := <-done:
+			results <- result
+		case <-ctx.Done():
+			results <- Result{Err: ctx.Err()}
+		}
+	}()
+
+	return results
+}
+
+// EvaluateAll evaluates requests with bounded concurrency and returns results in the order of requests.
+// Without FailFast all requests are evaluated and failures are reported only in their Result.
+func EvaluateAll(ctx context.Context, engine Engine, requests []Request, options EvaluateAllOptions) ([]Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	var failure error
+	var failureOnce sync.Once
+
+	results := make([]Result, len(requests))
+	parallel(len(requests), concurrency, func(index int) {
+		if err := ctx.Err(); err != nil {
+			results[index] = Result{Err: err}
+			return
+		}
+
+		request := requests[index]
+		response, err := engine.EvaluateWithOpts(request.Key, request.Input, request.Options)
+		results[index] = Result{Response: response, Err: err}
+
+		if err != nil && options.FailFast {
+			failureOnce.Do(func() {
+				failure = err
+				cancel()
+			})
+		}
+	})
+
+	if failure != nil {
+		return results, failure
+	}
+
+	return results, ctx.Err()
+}
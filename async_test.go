@@ -0,0 +1,87 @@
+package zen_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gorules/zen-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEvaluateAsync(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	tableResult := zen.EvaluateAsync(context.Background(), engine, "table.json", map[string]any{"input": 15}, zen.EvaluationOptions{})
+	functionResult := zen.EvaluateAsync(context.Background(), engine, "function.json", map[string]any{"input": 5}, zen.EvaluationOptions{Trace: true})
+
+	table := <-tableResult
+	assert.NoError(t, table.Err)
+	assert.JSONEq(t, `{"output":10}`, string(table.Response.Result))
+
+	function := <-functionResult
+	assert.NoError(t, function.Err)
+	assert.JSONEq(t, `{"output":10}`, string(function.Response.Result))
+	assert.NotNil(t, function.Response.Trace)
+
+	_, open := <-tableResult
+	assert.False(t, open)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cancelled := <-zen.EvaluateAsync(ctx, engine, "table.json", map[string]any{"input": 15}, zen.EvaluationOptions{})
+	assert.ErrorIs(t, cancelled.Err, context.Canceled)
+	assert.Nil(t, cancelled.Response)
+}
+
+func TestEvaluateAll(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	type responseData struct {
+		Output int `json:"output"`
+	}
+
+	requests := make([]zen.Request, 50)
+	for i := range requests {
+		requests[i] = zen.Request{Key: "function.json", Input: map[string]any{"input": i}}
+	}
+	requests[25] = zen.Request{Key: "missing.json"}
+
+	results, err := zen.EvaluateAll(context.Background(), engine, requests, zen.EvaluateAllOptions{Concurrency: 4})
+	assert.NoError(t, err)
+	assert.Len(t, results, len(requests))
+
+	for i, result := range results {
+		if i == 25 {
+			assert.Error(t, result.Err)
+			continue
+		}
+
+		assert.NoError(t, result.Err)
+
+		var respData responseData
+		assert.NoError(t, json.Unmarshal(result.Response.Result, &respData))
+		assert.Equal(t, i*2, respData.Output)
+	}
+}
+
+func TestEvaluateAll_FailFast(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	requests := make([]zen.Request, 50)
+	for i := range requests {
+		requests[i] = zen.Request{Key: "function.json", Input: map[string]any{"input": i}}
+	}
+	requests[0] = zen.Request{Key: "missing.json"}
+
+	results, err := zen.EvaluateAll(context.Background(), engine, requests, zen.EvaluateAllOptions{Concurrency: 1, FailFast: true})
+	assert.Error(t, err)
+	assert.Equal(t, results[0].Err, err)
+
+	for _, result := range results[1:] {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
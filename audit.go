@@ -0,0 +1,218 @@
+package zen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const modulePath = "github.com/gorules/zen-go"
+
+// AuditConfig enables recording of every evaluation made by the engine and its decisions.
+type AuditConfig struct {
+	// Sink receives a record per evaluation. Evaluation fails if the record cannot be written.
+	Sink AuditSink
+	// IncludeContent stores decision content in records, so Replay does not depend on the loader.
+	IncludeContent bool
+}
+
+// AuditRecord describes a single evaluation, and holds everything Replay needs to reproduce it.
+type AuditRecord struct {
+	Key string `json:"key,omitempty"`
+	// ContentHash is the hex encoded SHA-256 of decision content, nested decisions are not included.
+	ContentHash string          `json:"contentHash,omitempty"`
+	Content     json.RawMessage `json:"content,omitempty"`
+	// Options are the evaluation options used, per-evaluation overrides are not recorded.
+	Options     EvaluationOptions `json:"options"`
+	Input       json.RawMessage   `json:"input"`
+	Output      json.RawMessage   `json:"output,omitempty"`
+	Trace       *json.RawMessage  `json:"trace,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	Performance string            `json:"performance,omitempty"`
+	// EvaluatedAt is the wall clock time evaluation started at. The engine reads the current time on its own, so
+	// decisions depending on it may evaluate differently on Replay.
+	EvaluatedAt time.Time `json:"evaluatedAt"`
+	// ModuleVersion is the version of this Go module, see ModuleVersion.
+	ModuleVersion string `json:"moduleVersion"`
+}
+
+type AuditSink interface {
+	WriteRecord(record AuditRecord) error
+}
+
+type writerAuditSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewWriterAuditSink writes records to w as JSON lines, writes are serialized.
+func NewWriterAuditSink(w io.Writer) AuditSink {
+	return &writerAuditSink{encoder: json.NewEncoder(w)}
+}
+
+func (sink *writerAuditSink) WriteRecord(record AuditRecord) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	return sink.encoder.Encode(record)
+}
+
+// FileAuditSink appends records to a JSON lines file, every record is synced to disk before evaluation returns.
+type FileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileAuditSink{file: file}, nil
+}
+
+func (sink *FileAuditSink) WriteRecord(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	if _, err := sink.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return sink.file.Sync()
+}
+
+func (sink *FileAuditSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	return sink.file.Close()
+}
+
+// ReadAuditRecords reads records written by audit sinks, for example to Replay them.
+func ReadAuditRecords(r io.Reader) ([]AuditRecord, error) {
+	var records []AuditRecord
+	decoder := json.NewDecoder(r)
+	for {
+		var record AuditRecord
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return records, nil
+			}
+
+			return records, err
+		}
+
+		records = append(records, record)
+	}
+}
+
+type auditContent struct {
+	hash    string
+	content []byte
+}
+
+// auditor records evaluations to AuditConfig.Sink, it does nothing when auditing is not configured.
+type auditor struct {
+	config AuditConfig
+}
+
+func newAuditor(config *AuditConfig) *auditor {
+	if config == nil || config.Sink == nil {
+		return nil
+	}
+
+	return &auditor{config: *config}
+}
+
+func (a *auditor) content(data []byte) auditContent {
+	if a == nil {
+		return auditContent{}
+	}
+
+	content := auditContent{hash: contentHash(data)}
+	if a.config.IncludeContent {
+		content.content = bytes.Clone(data)
+	}
+
+	return content
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// record copies input, as evaluation inputs live in pooled buffers.
+func (a *auditor) record(key string, content auditContent, options EvaluationOptions, input []byte, evaluatedAt time.Time, response *EvaluationResponse, evaluationErr error) error {
+	if a == nil {
+		return nil
+	}
+
+	record := AuditRecord{
+		Key:           key,
+		ContentHash:   content.hash,
+		Content:       content.content,
+		Options:       options.withoutOverrides(),
+		Input:         bytes.Clone(input),
+		EvaluatedAt:   evaluatedAt,
+		ModuleVersion: ModuleVersion(),
+	}
+
+	if evaluationErr != nil {
+		record.Error = evaluationErr.Error()
+	} else {
+		record.Output = response.Result
+		record.Trace = response.Trace
+		record.Performance = response.Performance
+	}
+
+	if err := a.config.Sink.WriteRecord(record); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	return nil
+}
+
+var moduleVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+
+	for _, dependency := range info.Deps {
+		if dependency.Path == modulePath {
+			if dependency.Replace != nil && dependency.Replace.Version != "" {
+				return dependency.Replace.Version
+			}
+
+			return dependency.Version
+		}
+	}
+
+	return "unknown"
+})
+
+// ModuleVersion returns version of the zen-go Go module as recorded in the build info of the running binary. It is not
+// the version of the native engine linked into the binary, which the build info does not record.
+func ModuleVersion() string {
+	return moduleVersion()
+}
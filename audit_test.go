@@ -0,0 +1,232 @@
+package zen_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gorules/zen-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_Audit(t *testing.T) {
+	var records bytes.Buffer
+	engine := zen.NewEngine(zen.EngineConfig{
+		Loader: readTestFile,
+		Audit:  &zen.AuditConfig{Sink: zen.NewWriterAuditSink(&records)},
+	})
+	defer engine.Dispose()
+
+	_, err := engine.Evaluate("table.json", map[string]any{"input": 15})
+	assert.NoError(t, err)
+
+	decision, err := engine.GetDecision("table.json")
+	assert.NoError(t, err)
+	_, err = decision.EvaluateWithOpts(map[string]any{"input": 5}, zen.EvaluationOptions{Trace: true})
+	assert.NoError(t, err)
+	decision.Dispose()
+
+	_, err = engine.Evaluate("missing.json", map[string]any{"input": 5})
+	assert.Error(t, err)
+
+	content, err := readTestFile("table.json")
+	assert.NoError(t, err)
+	sum := sha256.Sum256(content)
+
+	read, err := zen.ReadAuditRecords(&records)
+	assert.NoError(t, err)
+	assert.Len(t, read, 3)
+
+	for _, record := range read[:2] {
+		assert.Equal(t, "table.json", record.Key)
+		assert.Equal(t, hex.EncodeToString(sum[:]), record.ContentHash)
+		assert.Empty(t, record.Content)
+		assert.NotEmpty(t, record.Output)
+		assert.NotEmpty(t, record.ModuleVersion)
+		assert.False(t, record.EvaluatedAt.IsZero())
+	}
+
+	assert.JSONEq(t, `{"input":15}`, string(read[0].Input))
+	assert.Nil(t, read[0].Trace)
+	assert.True(t, read[1].Options.Trace)
+	assert.NotNil(t, read[1].Trace)
+
+	assert.Equal(t, "missing.json", read[2].Key)
+	assert.NotEmpty(t, read[2].Error)
+	assert.Empty(t, read[2].Output)
+}
+
+func TestEngine_AuditConcurrentLoads(t *testing.T) {
+	content, err := readTestFile("table.json")
+	assert.NoError(t, err)
+
+	// every load serves different content, and every third load fails
+	var mu sync.Mutex
+	var loads int
+	loaded := make(map[string]bool)
+	var records bytes.Buffer
+	engine := zen.NewEngine(zen.EngineConfig{
+		Loader: func(key string) ([]byte, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			loads++
+			if loads%3 == 0 {
+				return nil, errors.New("unavailable")
+			}
+
+			data := append(bytes.Clone(content), bytes.Repeat([]byte(" "), loads)...)
+			sum := sha256.Sum256(data)
+			loaded[hex.EncodeToString(sum[:])] = true
+			return data, nil
+		},
+		Audit: &zen.AuditConfig{Sink: zen.NewWriterAuditSink(&records)},
+	})
+	defer engine.Dispose()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = engine.Evaluate("table.json", map[string]any{"input": 15})
+		}()
+	}
+	wg.Wait()
+
+	read, err := zen.ReadAuditRecords(&records)
+	assert.NoError(t, err)
+	assert.Len(t, read, 30)
+
+	hashes := make(map[string]bool)
+	for _, record := range read {
+		if record.Error != "" {
+			assert.Empty(t, record.ContentHash, "failed load has no content")
+			continue
+		}
+
+		assert.True(t, loaded[record.ContentHash], "hash of content served to the evaluation")
+		assert.False(t, hashes[record.ContentHash], "every evaluation loads its own content")
+		hashes[record.ContentHash] = true
+	}
+	assert.Len(t, hashes, len(loaded))
+}
+
+func TestDecision_AuditOverrides(t *testing.T) {
+	var records bytes.Buffer
+	engine := zen.NewEngine(zen.EngineConfig{
+		Loader: readTestFile,
+		Audit:  &zen.AuditConfig{Sink: zen.NewWriterAuditSink(&records)},
+	})
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("table.json")
+	assert.NoError(t, err)
+	defer decision.Dispose()
+
+	_, err = decision.EvaluateWithOpts(map[string]any{"input": 5}, zen.EvaluationOptions{CustomNodeHandler: customNodeHandler})
+	assert.NoError(t, err)
+
+	content, err := readTestFile("table.json")
+	assert.NoError(t, err)
+	sum := sha256.Sum256(content)
+
+	read, err := zen.ReadAuditRecords(&records)
+	assert.NoError(t, err)
+	assert.Len(t, read, 1)
+	assert.Equal(t, "table.json", read[0].Key, "recreated decision keeps its key")
+	assert.Equal(t, hex.EncodeToString(sum[:]), read[0].ContentHash)
+}
+
+func TestEngine_AuditFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := zen.NewFileAuditSink(path)
+	assert.NoError(t, err)
+
+	engine := zen.NewEngine(zen.EngineConfig{Audit: &zen.AuditConfig{Sink: sink, IncludeContent: true}})
+	defer engine.Dispose()
+
+	content, err := readTestFile("table.json")
+	assert.NoError(t, err)
+
+	decision, err := engine.CreateDecision(content)
+	assert.NoError(t, err)
+	defer decision.Dispose()
+
+	for i := 0; i < 2; i++ {
+		_, err = decision.Evaluate(map[string]any{"input": i})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	records, err := zen.ReadAuditRecords(file)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.JSONEq(t, string(content), string(records[0].Content))
+}
+
+func TestReplay(t *testing.T) {
+	var records bytes.Buffer
+	engine := zen.NewEngine(zen.EngineConfig{
+		Loader: readTestFile,
+		Audit:  &zen.AuditConfig{Sink: zen.NewWriterAuditSink(&records)},
+	})
+	defer engine.Dispose()
+
+	_, err := engine.Evaluate("table.json", map[string]any{"input": 15})
+	assert.NoError(t, err)
+
+	read, err := zen.ReadAuditRecords(&records)
+	assert.NoError(t, err)
+	record := read[0]
+
+	result, err := zen.Replay(record, zen.EngineConfig{Loader: readTestFile})
+	assert.NoError(t, err)
+	assert.True(t, result.Matches())
+	assert.False(t, result.ContentChanged)
+
+	record.Output = json.RawMessage(`{"output":-1}`)
+	record.ContentHash = "changed"
+	result, err = zen.Replay(record, zen.EngineConfig{Loader: readTestFile})
+	assert.NoError(t, err)
+	assert.False(t, result.Matches())
+	assert.True(t, result.ContentChanged)
+	assert.Equal(t, "output", result.Diffs[0].Path)
+	assert.JSONEq(t, `-1`, string(result.Diffs[0].Expected))
+
+	_, err = zen.Replay(zen.AuditRecord{Key: "table.json"}, zen.EngineConfig{})
+	assert.Error(t, err)
+}
+
+func TestDiffJSON(t *testing.T) {
+	diffs, err := zen.DiffJSON(
+		[]byte(`{"a":1,"b":{"c":"x","d":[1,2,3]},"e":true,"f.g":1}`),
+		[]byte(`{"a":1.0,"b":{"c":"y","d":[1,2]},"h":null,"f.g":2}`),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []zen.FieldDiff{
+		{Path: "b.c", Expected: json.RawMessage(`"x"`), Actual: json.RawMessage(`"y"`)},
+		{Path: "b.d.2", Expected: json.RawMessage(`3`)},
+		{Path: "e", Expected: json.RawMessage(`true`)},
+		{Path: `f\.g`, Expected: json.RawMessage(`1`), Actual: json.RawMessage(`2`)},
+		{Path: "h", Actual: json.RawMessage(`null`)},
+	}, diffs)
+
+	diffs, err = zen.DiffJSON([]byte(`{"a":[1]}`), []byte(`{"a":[1]}`))
+	assert.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	diffs, err = zen.DiffJSON([]byte(`1`), []byte(`"1"`))
+	assert.NoError(t, err)
+	assert.Equal(t, []zen.FieldDiff{{Path: "", Expected: json.RawMessage(`1`), Actual: json.RawMessage(`"1"`)}}, diffs)
+}
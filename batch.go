@@ -0,0 +1,60 @@
+package zen
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// BatchResult holds outcome of a single batch item, results are returned in the order of inputs.
+type BatchResult = Result
+
+// EvaluateBatch evaluates contexts with decision.EvaluateBatch when decision is a BatchEvaluator, otherwise calls
+// EvaluateWithOpts for every context in parallel. Failure of a single item is reported in its BatchResult.
+func EvaluateBatch(decision Decision, contexts []any, options EvaluationOptions) ([]BatchResult, error) {
+	if batchEvaluator, ok := decision.(BatchEvaluator); ok {
+		return batchEvaluator.EvaluateBatch(contexts, options)
+	}
+
+	return evaluateBatch(contexts, func(context any) (*EvaluationResponse, error) {
+		return decision.EvaluateWithOpts(context, options)
+	}), nil
+}
+
+// evaluateBatch runs evaluate for every context using a worker pool sized to GOMAXPROCS.
+func evaluateBatch(contexts []any, evaluate func(context any) (*EvaluationResponse, error)) []BatchResult {
+	results := make([]BatchResult, len(contexts))
+	parallel(len(contexts), runtime.GOMAXPROCS(0), func(index int) {
+		response, err := evaluate(contexts[index])
+		results[index] = BatchResult{Response: response, Err: err}
+	})
+
+	return results
+}
+
+// parallel calls fn for every index in [0, n) from at most workers goroutines, and waits for all of them.
+func parallel(n int, workers int, fn func(index int)) {
+	if workers > n {
+		workers = n
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				index := int(next.Add(1) - 1)
+				if index >= n {
+					return
+				}
+
+				fn(index)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
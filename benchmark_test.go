@@ -3,6 +3,7 @@ package zen_test
 import (
 	"github.com/gorules/zen-go"
 	"github.com/stretchr/testify/require"
+	"runtime"
 	"testing"
 )
 
@@ -12,6 +13,23 @@ func BenchmarkEngine(b *testing.B) {
 
 	context := map[string]any{"input": 5}
 
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = engine.Evaluate("table.json", context)
+	}
+}
+
+func BenchmarkEngineCached(b *testing.B) {
+	engine := zen.NewEngine(zen.EngineConfig{
+		Loader:            readTestFile,
+		CustomNodeHandler: customNodeHandler,
+		DecisionCache:     &zen.DecisionCacheConfig{MaxSize: 100},
+	})
+	defer engine.Dispose()
+
+	context := map[string]any{"input": 5}
+
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		_, _ = engine.Evaluate("table.json", context)
 	}
@@ -27,6 +45,44 @@ func BenchmarkDecision(b *testing.B) {
 
 	context := map[string]any{"input": 5}
 
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = decision.Evaluate(context)
+	}
+}
+
+func BenchmarkDecisionBytes(b *testing.B) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("table.json")
+	require.NoError(b, err)
+	defer decision.Dispose()
+
+	context := []byte(`{"input":5}`)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = decision.Evaluate(context)
+	}
+}
+
+func BenchmarkDecisionLargeInput(b *testing.B) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("table.json")
+	require.NoError(b, err)
+	defer decision.Dispose()
+
+	items := make([]map[string]any, 1000)
+	for i := range items {
+		items[i] = map[string]any{"id": i, "name": "item", "tags": []string{"a", "b", "c"}}
+	}
+
+	context := map[string]any{"input": 5, "items": items}
+
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		_, _ = decision.Evaluate(context)
 	}
@@ -42,7 +98,63 @@ func BenchmarkDecisionCustomNode(b *testing.B) {
 
 	context := map[string]any{"a": 5, "b": 10, "c": 15}
 
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		_, _ = decision.Evaluate(context)
 	}
 }
+
+func BenchmarkDecisionBatch(b *testing.B) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("table.json")
+	require.NoError(b, err)
+	defer decision.Dispose()
+
+	contexts := make([]any, 1000)
+	for i := range contexts {
+		contexts[i] = map[string]any{"input": i % 20}
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = zen.EvaluateBatch(decision, contexts, zen.EvaluationOptions{})
+	}
+}
+
+// benchmarkParallelEngine runs evaluations from thousands of goroutines at once.
+func benchmarkParallelEngine(b *testing.B, engine zen.Engine) {
+	context := map[string]any{"input": 5}
+
+	b.SetParallelism(4096 / runtime.GOMAXPROCS(0))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = engine.Evaluate("table.json", context)
+		}
+	})
+}
+
+func BenchmarkEngine_EvaluateParallel(b *testing.B) {
+	engine := zen.NewEngine(zen.EngineConfig{
+		Loader:            readTestFile,
+		CustomNodeHandler: customNodeHandler,
+		DecisionCache:     &zen.DecisionCacheConfig{},
+	})
+	defer engine.Dispose()
+
+	benchmarkParallelEngine(b, engine)
+}
+
+func BenchmarkEnginePool_EvaluateParallel(b *testing.B) {
+	pool := zen.NewEnginePool(zen.EngineConfig{
+		Loader:            readTestFile,
+		CustomNodeHandler: customNodeHandler,
+		DecisionCache:     &zen.DecisionCacheConfig{},
+	}, runtime.GOMAXPROCS(0))
+	defer pool.Dispose()
+
+	benchmarkParallelEngine(b, pool)
+}
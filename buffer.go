@@ -0,0 +1,110 @@
+package zen
+
+// #include <string.h>
+// #include "zen_engine.h"
+import "C"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/tidwall/gjson"
+)
+
+// maxPooledBufferSize prevents occasional large inputs from being retained by the pool.
+const maxPooledBufferSize = 1 << 20
+
+var jsonBufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// jsonBuffer holds NUL-terminated JSON in pooled Go memory, so it can be passed to C without copying it again.
+type jsonBuffer struct {
+	buffer *bytes.Buffer
+}
+
+func newJsonBuffer(codec Codec, data any) (jsonBuffer, error) {
+	buffer := jsonBufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+
+	switch d := data.(type) {
+	case []byte:
+		buffer.Write(d)
+	case json.RawMessage:
+		buffer.Write(d)
+	default:
+		if err := encodeInto(codec, buffer, data); err != nil {
+			jsonBufferPool.Put(buffer)
+			return jsonBuffer{}, err
+		}
+	}
+
+	buffer.WriteByte(0)
+	return jsonBuffer{buffer: buffer}, nil
+}
+
+// bytes returns JSON without NUL terminator.
+func (b jsonBuffer) bytes() []byte {
+	data := b.buffer.Bytes()
+	return data[:len(data)-1]
+}
+
+// cString is valid only for the duration of a C call which does not retain it, and until release is called.
+func (b jsonBuffer) cString() *C.char {
+	return (*C.char)(unsafe.Pointer(&b.buffer.Bytes()[0]))
+}
+
+func (b jsonBuffer) release() {
+	if b.buffer.Cap() <= maxPooledBufferSize {
+		jsonBufferPool.Put(b.buffer)
+	}
+}
+
+// cStringFromBytes copies data into C memory adding NUL terminator, caller is responsible for freeing it.
+func cStringFromBytes(data []byte) *C.char {
+	ptr := (*C.char)(C.malloc(C.size_t(len(data) + 1)))
+	buffer := unsafe.Slice((*byte)(unsafe.Pointer(ptr)), len(data)+1)
+	copy(buffer, data)
+	buffer[len(data)] = 0
+
+	return ptr
+}
+
+// goBytes copies NUL-terminated C string into Go memory with a single allocation.
+func goBytes(str *C.char) []byte {
+	return C.GoBytes(unsafe.Pointer(str), C.int(C.strlen(str)))
+}
+
+// newEvaluationResponse slices envelope fields out of data without decoding or copying them.
+func newEvaluationResponse(codec Codec, data []byte) (*EvaluationResponse, error) {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if len(data) == 0 || data[0] != '{' {
+		return nil, errors.New("invalid evaluation response")
+	}
+
+	response := EvaluationResponse{codec: codec}
+	gjson.Parse(unsafe.String(&data[0], len(data))).ForEach(func(key, value gjson.Result) bool {
+		raw := data[value.Index : value.Index+len(value.Raw) : value.Index+len(value.Raw)]
+
+		switch key.Str {
+		case "performance":
+			response.Performance = strings.Clone(value.Str)
+		case "result":
+			response.Result = raw
+		case "trace":
+			if value.Type != gjson.Null {
+				trace := json.RawMessage(raw)
+				response.Trace = &trace
+			}
+		}
+
+		return true
+	})
+
+	return &response, nil
+}
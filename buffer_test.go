@@ -0,0 +1,55 @@
+package zen
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestJsonBuffer(t *testing.T) {
+	type TestCase struct {
+		data   any
+		output string
+	}
+
+	testCases := []TestCase{
+		{data: map[string]any{"a": 1}, output: `{"a":1}`},
+		{data: []byte(`{"b": 2}`), output: `{"b": 2}`},
+		{data: json.RawMessage(`{"c":3}`), output: `{"c":3}`},
+		{data: nil, output: `null`},
+	}
+
+	for _, testCase := range testCases {
+		buffer, err := newJsonBuffer(JSONCodec{}, testCase.data)
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.output, string(buffer.bytes()))
+		assert.Equal(t, byte(0), buffer.buffer.Bytes()[buffer.buffer.Len()-1])
+		buffer.release()
+	}
+
+	_, err := newJsonBuffer(JSONCodec{}, make(chan int))
+	assert.Error(t, err)
+}
+
+func TestNewEvaluationResponse(t *testing.T) {
+	data := []byte(`{"performance":"1.2ms","result":{"output":10},"trace":{"node":{"id":"node"}}}`)
+
+	response, err := newEvaluationResponse(JSONCodec{}, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2ms", response.Performance)
+	assert.JSONEq(t, `{"output":10}`, string(response.Result))
+	assert.NotNil(t, response.Trace)
+	assert.JSONEq(t, `{"node":{"id":"node"}}`, string(*response.Trace))
+
+	// appending to result must not overwrite the rest of the envelope
+	_ = append(response.Result, "garbage"...)
+	assert.JSONEq(t, `{"node":{"id":"node"}}`, string(*response.Trace))
+
+	response, err = newEvaluationResponse(JSONCodec{}, []byte(`{"performance":"1ms","result":null,"trace":null}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "null", string(response.Result))
+	assert.Nil(t, response.Trace)
+
+	_, err = newEvaluationResponse(JSONCodec{}, []byte(`null`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package zen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Codec encodes inputs and custom node responses, and decodes results and custom node requests.
+// Implementations must be safe for concurrent use.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec is the default Codec backed by encoding/json.
+type JSONCodec struct {
+	// UseNumber decodes numbers into json.Number instead of float64 when target is an interface, preserving precision.
+	UseNumber bool
+}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (c JSONCodec) Unmarshal(data []byte, v any) error {
+	if !c.UseNumber {
+		return json.Unmarshal(data, v)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("invalid data after top-level value")
+	}
+
+	return nil
+}
+
+// encode writes v directly into the buffer, avoiding intermediate allocation made by Marshal.
+func (JSONCodec) encode(buffer *bytes.Buffer, v any) error {
+	if err := json.NewEncoder(buffer).Encode(v); err != nil {
+		return err
+	}
+
+	// Encoder terminates every value with a newline
+	buffer.Truncate(buffer.Len() - 1)
+	return nil
+}
+
+type bufferEncoder interface {
+	encode(buffer *bytes.Buffer, v any) error
+}
+
+func codecOrDefault(codec Codec) Codec {
+	if codec == nil {
+		return JSONCodec{}
+	}
+
+	return codec
+}
+
+func encodeInto(codec Codec, buffer *bytes.Buffer, v any) error {
+	if encoder, ok := codec.(bufferEncoder); ok {
+		return encoder.encode(buffer, v)
+	}
+
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	buffer.Write(data)
+	return nil
+}
@@ -0,0 +1,229 @@
+package zen
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CompareReport summarizes differences between baseline and candidate decisions over the same inputs.
+type CompareReport struct {
+	Total     int `json:"total"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+	// BaselineErrors and CandidateErrors count failed evaluations of either decision.
+	BaselineErrors  int `json:"baselineErrors"`
+	CandidateErrors int `json:"candidateErrors"`
+	// ErrorsIntroduced counts inputs failing only with candidate, ErrorsResolved only with baseline.
+	ErrorsIntroduced int           `json:"errorsIntroduced"`
+	ErrorsResolved   int           `json:"errorsResolved"`
+	Fields           []FieldChange `json:"fields"`
+	Differences      []Difference  `json:"differences"`
+	BaselineLatency  LatencyStats  `json:"baselineLatency"`
+	CandidateLatency LatencyStats  `json:"candidateLatency"`
+}
+
+// FieldChange counts inputs for which a field of the output changed.
+type FieldChange struct {
+	Path    string `json:"path"`
+	Changed int    `json:"changed"`
+}
+
+// Difference describes a single input whose outcome differs, Expected of Diffs refers to baseline.
+type Difference struct {
+	Index          int             `json:"index"`
+	Input          json.RawMessage `json:"input"`
+	Diffs          []FieldDiff     `json:"diffs,omitempty"`
+	BaselineError  string          `json:"baselineError,omitempty"`
+	CandidateError string          `json:"candidateError,omitempty"`
+}
+
+// LatencyStats are computed from wall time of every evaluation.
+type LatencyStats struct {
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P95  time.Duration `json:"p95"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+}
+
+// Compare evaluates every input with baseline and candidate and reports differences of their outcomes. Inputs are
+// compatible with iter.Seq[any]. Evaluations run sequentially, so latencies of both decisions are comparable.
+// When ctx is done, report of inputs evaluated so far is returned together with ctx error.
+func Compare(ctx context.Context, baseline, candidate Decision, inputs func(yield func(any) bool)) (CompareReport, error) {
+	var report CompareReport
+	var baselineLatencies, candidateLatencies []time.Duration
+	fields := make(map[string]int)
+
+	var err error
+	inputs(func(input any) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+
+		baselineResponse, baselineLatency, baselineErr := timedEvaluate(baseline, input)
+		candidateResponse, candidateLatency, candidateErr := timedEvaluate(candidate, input)
+		baselineLatencies = append(baselineLatencies, baselineLatency)
+		candidateLatencies = append(candidateLatencies, candidateLatency)
+
+		difference := Difference{Index: report.Total}
+		report.Total++
+
+		switch {
+		case baselineErr != nil || candidateErr != nil:
+			if baselineErr != nil {
+				report.BaselineErrors++
+				difference.BaselineError = baselineErr.Error()
+			}
+
+			if candidateErr != nil {
+				report.CandidateErrors++
+				difference.CandidateError = candidateErr.Error()
+			}
+
+			if baselineErr == nil {
+				report.ErrorsIntroduced++
+			} else if candidateErr == nil {
+				report.ErrorsResolved++
+			}
+
+			if difference.BaselineError == difference.CandidateError {
+				report.Unchanged++
+				return true
+			}
+		default:
+			if difference.Diffs, err = DiffJSON(baselineResponse.Result, candidateResponse.Result); err != nil {
+				return false
+			}
+
+			if len(difference.Diffs) == 0 {
+				report.Unchanged++
+				return true
+			}
+
+			for _, diff := range difference.Diffs {
+				fields[diff.Path]++
+			}
+		}
+
+		report.Changed++
+		if difference.Input, err = encodeInput(input); err != nil {
+			return false
+		}
+
+		report.Differences = append(report.Differences, difference)
+		return true
+	})
+
+	for path, changed := range fields {
+		report.Fields = append(report.Fields, FieldChange{Path: path, Changed: changed})
+	}
+
+	sort.Slice(report.Fields, func(i, j int) bool {
+		if report.Fields[i].Changed != report.Fields[j].Changed {
+			return report.Fields[i].Changed > report.Fields[j].Changed
+		}
+
+		return report.Fields[i].Path < report.Fields[j].Path
+	})
+
+	report.BaselineLatency = newLatencyStats(baselineLatencies)
+	report.CandidateLatency = newLatencyStats(candidateLatencies)
+	return report, err
+}
+
+// SliceInputs adapts a slice for Compare.
+func SliceInputs(inputs []any) func(yield func(any) bool) {
+	return func(yield func(any) bool) {
+		for _, input := range inputs {
+			if !yield(input) {
+				return
+			}
+		}
+	}
+}
+
+func timedEvaluate(decision Decision, input any) (*EvaluationResponse, time.Duration, error) {
+	start := time.Now()
+	response, err := decision.Evaluate(input)
+	return response, time.Since(start), err
+}
+
+func encodeInput(input any) (json.RawMessage, error) {
+	switch data := input.(type) {
+	case json.RawMessage:
+		return data, nil
+	case []byte:
+		return data, nil
+	default:
+		return json.Marshal(input)
+	}
+}
+
+func newLatencyStats(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+
+	percentile := func(p int) time.Duration {
+		return latencies[(len(latencies)-1)*p/100]
+	}
+
+	return LatencyStats{
+		Mean: total / time.Duration(len(latencies)),
+		P50:  percentile(50),
+		P95:  percentile(95),
+		P99:  percentile(99),
+		Max:  latencies[len(latencies)-1],
+	}
+}
+
+// WriteJSON writes the whole report as indented JSON.
+func (r CompareReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes a row per changed field of every difference, errors are reported on rows with an empty path.
+func (r CompareReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"index", "path", "baseline", "candidate", "baseline_error", "candidate_error", "input"}); err != nil {
+		return err
+	}
+
+	for _, difference := range r.Differences {
+		index := strconv.Itoa(difference.Index)
+		if len(difference.Diffs) == 0 {
+			row := []string{index, "", "", "", difference.BaselineError, difference.CandidateError, string(difference.Input)}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		for _, diff := range difference.Diffs {
+			row := []string{index, diff.Path, string(diff.Expected), string(diff.Actual), "", "", string(difference.Input)}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,138 @@
+package zen_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gorules/zen-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// funcDecision evaluates inputs with a Go function, so comparisons and shadow evaluations do not depend on the engine.
+type funcDecision struct {
+	evaluate func(input map[string]any) (string, error)
+}
+
+func (decision funcDecision) Evaluate(context any) (*zen.EvaluationResponse, error) {
+	return decision.EvaluateWithOpts(context, zen.EvaluationOptions{})
+}
+
+func (decision funcDecision) EvaluateWithOpts(context any, _ zen.EvaluationOptions) (*zen.EvaluationResponse, error) {
+	var input map[string]any
+	switch data := context.(type) {
+	case map[string]any:
+		input = data
+	case json.RawMessage:
+		// shadow candidates receive encoded inputs
+		if err := json.Unmarshal(data, &input); err != nil {
+			return nil, err
+		}
+	case []byte:
+		if err := json.Unmarshal(data, &input); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := decision.evaluate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zen.EvaluationResponse{Result: json.RawMessage(result)}, nil
+}
+
+func (decision funcDecision) Dispose() {}
+
+func TestCompare(t *testing.T) {
+	baseline := funcDecision{evaluate: func(input map[string]any) (string, error) {
+		if input["age"].(int) < 0 {
+			return "", errors.New("invalid age")
+		}
+
+		if input["age"].(int) >= 65 {
+			return `{"rate": 0.05, "tier": "senior"}`, nil
+		}
+
+		return `{"rate": 0.1, "tier": "standard"}`, nil
+	}}
+	candidate := funcDecision{evaluate: func(input map[string]any) (string, error) {
+		if input["age"].(int) > 100 {
+			return "", errors.New("age out of range")
+		}
+
+		if input["age"].(int) >= 60 {
+			return `{"rate": 0.04, "tier": "senior"}`, nil
+		}
+
+		return `{"rate": 0.10, "tier": "standard"}`, nil
+	}}
+
+	inputs := []any{
+		map[string]any{"age": 30},
+		map[string]any{"age": 62},
+		map[string]any{"age": 70},
+		map[string]any{"age": -1},
+		map[string]any{"age": 120},
+	}
+
+	report, err := zen.Compare(context.Background(), baseline, candidate, zen.SliceInputs(inputs))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 4, report.Changed)
+	assert.Equal(t, 1, report.Unchanged)
+	assert.Equal(t, 1, report.BaselineErrors)
+	assert.Equal(t, 1, report.CandidateErrors)
+	assert.Equal(t, 1, report.ErrorsIntroduced)
+	assert.Equal(t, 1, report.ErrorsResolved)
+	assert.Equal(t, []zen.FieldChange{{Path: "rate", Changed: 2}, {Path: "tier", Changed: 1}}, report.Fields)
+
+	assert.Len(t, report.Differences, 4)
+	assert.Equal(t, 1, report.Differences[0].Index)
+	assert.JSONEq(t, `{"age": 62}`, string(report.Differences[0].Input))
+	assert.Equal(t, "invalid age", report.Differences[2].BaselineError)
+	assert.Equal(t, "age out of range", report.Differences[3].CandidateError)
+	assert.LessOrEqual(t, report.BaselineLatency.P50, report.BaselineLatency.Max)
+
+	var csv bytes.Buffer
+	assert.NoError(t, report.WriteCSV(&csv))
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	assert.Equal(t, "index,path,baseline,candidate,baseline_error,candidate_error,input", lines[0])
+	assert.Equal(t, `1,rate,0.1,0.04,,,"{""age"":62}"`, lines[1])
+	assert.Len(t, lines, 6)
+
+	var data bytes.Buffer
+	assert.NoError(t, report.WriteJSON(&data))
+	var decoded zen.CompareReport
+	assert.NoError(t, json.Unmarshal(data.Bytes(), &decoded))
+	assert.Equal(t, report.Fields, decoded.Fields)
+}
+
+func TestCompare_Canceled(t *testing.T) {
+	decision := funcDecision{evaluate: func(input map[string]any) (string, error) {
+		return `{}`, nil
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	evaluated := 0
+	inputs := func(yield func(any) bool) {
+		for {
+			evaluated++
+			if evaluated == 3 {
+				cancel()
+			}
+
+			if !yield(map[string]any{}) {
+				return
+			}
+		}
+	}
+
+	report, err := zen.Compare(ctx, decision, decision, inputs)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 2, report.Unchanged)
+}
@@ -0,0 +1,420 @@
+package zen
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+// Coverage aggregates traces of many evaluations and reports which nodes, decision table rules and switch statements
+// were hit. Decisions must be registered before their evaluations are recorded, WrapLoader does so automatically.
+type Coverage struct {
+	mu        sync.Mutex
+	decisions map[string]*decisionCoverage
+}
+
+type decisionCoverage struct {
+	hash  string
+	nodes []*NodeCoverage
+	byID  map[string]*NodeCoverage
+}
+
+// CoverageReport is a snapshot of Coverage, encodable as JSON.
+type CoverageReport struct {
+	Decisions []DecisionCoverage `json:"decisions"`
+	Covered   int                `json:"covered"`
+	Total     int                `json:"total"`
+}
+
+type DecisionCoverage struct {
+	Key     string         `json:"key"`
+	Nodes   []NodeCoverage `json:"nodes"`
+	Covered int            `json:"covered"`
+	Total   int            `json:"total"`
+}
+
+// NodeCoverage counts hits of a node. Decision tables and switches are covered by their rules and statements,
+// other nodes by being visited at all.
+type NodeCoverage struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Hits       int            `json:"hits"`
+	Rules      []RuleCoverage `json:"rules,omitempty"`
+	Statements []RuleCoverage `json:"statements,omitempty"`
+}
+
+// RuleCoverage counts hits of a decision table rule or a switch statement.
+type RuleCoverage struct {
+	ID          string `json:"id"`
+	Index       int    `json:"index"`
+	Description string `json:"description,omitempty"`
+	Hits        int    `json:"hits"`
+}
+
+// UncoveredItem is a node, rule or statement never hit, see CoverageReport.Uncovered.
+type UncoveredItem struct {
+	Key      string `json:"key"`
+	NodeID   string `json:"nodeId"`
+	NodeName string `json:"nodeName"`
+	// RuleID is empty when the node itself was never visited.
+	RuleID    string `json:"ruleId,omitempty"`
+	RuleIndex int    `json:"ruleIndex"`
+}
+
+type jdmContent struct {
+	Nodes []struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Type    string `json:"type"`
+		Content struct {
+			Rules []struct {
+				ID          string `json:"_id"`
+				Description string `json:"_description"`
+			} `json:"rules"`
+			Statements []struct {
+				ID string `json:"id"`
+			} `json:"statements"`
+		} `json:"content"`
+	} `json:"nodes"`
+	Edges []struct {
+		SourceID string `json:"sourceId"`
+		TargetID string `json:"targetId"`
+	} `json:"edges"`
+}
+
+func NewCoverage() *Coverage {
+	return &Coverage{decisions: make(map[string]*decisionCoverage)}
+}
+
+// Register reads nodes, rules and statements of JDM content. Registering a key again with changed content keeps hits
+// of nodes, rules and statements which still exist, registering unchanged content is a no-op.
+func (c *Coverage) Register(key string, content []byte) error {
+	hash := contentHash(content)
+	c.mu.Lock()
+	existing, ok := c.decisions[key]
+	c.mu.Unlock()
+	if ok && existing.hash == hash {
+		return nil
+	}
+
+	var jdm jdmContent
+	if err := json.Unmarshal(content, &jdm); err != nil {
+		return err
+	}
+
+	decision := &decisionCoverage{hash: hash, byID: make(map[string]*NodeCoverage)}
+	for _, node := range jdm.Nodes {
+		nodeCoverage := &NodeCoverage{ID: node.ID, Name: node.Name, Type: node.Type}
+		switch node.Type {
+		case "decisionTableNode":
+			for i, rule := range node.Content.Rules {
+				nodeCoverage.Rules = append(nodeCoverage.Rules, RuleCoverage{ID: rule.ID, Index: i, Description: rule.Description})
+			}
+		case "switchNode":
+			for i, statement := range node.Content.Statements {
+				nodeCoverage.Statements = append(nodeCoverage.Statements, RuleCoverage{ID: statement.ID, Index: i})
+			}
+		}
+
+		decision.nodes = append(decision.nodes, nodeCoverage)
+		decision.byID[node.ID] = nodeCoverage
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if previous, ok := c.decisions[key]; ok {
+		if previous.hash == hash {
+			return nil
+		}
+
+		decision.carryHits(previous)
+	}
+
+	c.decisions[key] = decision
+	return nil
+}
+
+// carryHits copies hits of nodes, rules and statements which exist in both versions of a decision.
+func (d *decisionCoverage) carryHits(previous *decisionCoverage) {
+	for _, node := range d.nodes {
+		previousNode, ok := previous.byID[node.ID]
+		if !ok {
+			continue
+		}
+
+		node.Hits = previousNode.Hits
+		carryRuleHits(node.Rules, previousNode.Rules)
+		carryRuleHits(node.Statements, previousNode.Statements)
+	}
+}
+
+func carryRuleHits(rules, previous []RuleCoverage) {
+	for i := range rules {
+		for _, previousRule := range previous {
+			if (rules[i].ID != "" && rules[i].ID == previousRule.ID) || (rules[i].ID == "" && rules[i].Index == previousRule.Index) {
+				rules[i].Hits = previousRule.Hits
+				break
+			}
+		}
+	}
+}
+
+// WrapLoader registers every decision loaded by loader.
+func (c *Coverage) WrapLoader(loader Loader) Loader {
+	return func(key string) ([]byte, error) {
+		content, err := loader(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.Register(key, content); err != nil {
+			return nil, err
+		}
+
+		return content, nil
+	}
+}
+
+// Record adds hits from the trace of response, which must be evaluated with Trace enabled.
+func (c *Coverage) Record(key string, response *EvaluationResponse) error {
+	if response == nil || response.Trace == nil {
+		return fmt.Errorf("response of %q has no trace, evaluate with Trace enabled", key)
+	}
+
+	trace := gjson.ParseBytes(*response.Trace)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	decision, ok := c.decisions[key]
+	if !ok {
+		return fmt.Errorf("decision %q is not registered", key)
+	}
+
+	trace.ForEach(func(key, node gjson.Result) bool {
+		nodeID := node.Get("id").String()
+		if nodeID == "" {
+			nodeID = key.String()
+		}
+
+		nodeCoverage, ok := decision.byID[nodeID]
+		if !ok {
+			return true
+		}
+
+		nodeCoverage.Hits++
+		traceData := node.Get("traceData")
+		switch {
+		case isTableTrace(traceData):
+			rows := []gjson.Result{traceData}
+			if traceData.IsArray() {
+				rows = traceData.Array()
+			}
+
+			for _, row := range rows {
+				hitRule(nodeCoverage.Rules, row.Get("rule._id").String(), int(row.Get("index").Int()))
+			}
+		case traceData.Get("statements").IsArray():
+			traceData.Get("statements").ForEach(func(_, statement gjson.Result) bool {
+				hitRule(nodeCoverage.Statements, statement.Get("id").String(), -1)
+				return true
+			})
+		}
+
+		return true
+	})
+
+	return nil
+}
+
+// hitRule matches by id, falling back to index for rules without one.
+func hitRule(rules []RuleCoverage, id string, index int) {
+	for i := range rules {
+		if (id != "" && rules[i].ID == id) || (id == "" && rules[i].Index == index) {
+			rules[i].Hits++
+			return
+		}
+	}
+}
+
+// Reset clears hits of all registered decisions.
+func (c *Coverage) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, decision := range c.decisions {
+		for _, node := range decision.nodes {
+			node.Hits = 0
+			for i := range node.Rules {
+				node.Rules[i].Hits = 0
+			}
+
+			for i := range node.Statements {
+				node.Statements[i].Hits = 0
+			}
+		}
+	}
+}
+
+func (c *Coverage) Report() CoverageReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, len(c.decisions))
+	for key := range c.decisions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var report CoverageReport
+	for _, key := range keys {
+		decision := DecisionCoverage{Key: key}
+		for _, node := range c.decisions[key].nodes {
+			nodeCoverage := *node
+			nodeCoverage.Rules = append([]RuleCoverage(nil), node.Rules...)
+			nodeCoverage.Statements = append([]RuleCoverage(nil), node.Statements...)
+
+			covered, total := nodeCoverage.coverage()
+			decision.Covered += covered
+			decision.Total += total
+			decision.Nodes = append(decision.Nodes, nodeCoverage)
+		}
+
+		report.Covered += decision.Covered
+		report.Total += decision.Total
+		report.Decisions = append(report.Decisions, decision)
+	}
+
+	return report
+}
+
+func (n NodeCoverage) coverage() (covered, total int) {
+	items := append(append([]RuleCoverage(nil), n.Rules...), n.Statements...)
+	if len(items) == 0 {
+		if n.Hits > 0 {
+			return 1, 1
+		}
+
+		return 0, 1
+	}
+
+	for _, item := range items {
+		if item.Hits > 0 {
+			covered++
+		}
+	}
+
+	return covered, len(items)
+}
+
+// Percent returns share of covered items, 100 when there is nothing to cover.
+func (r CoverageReport) Percent() float64 {
+	return percent(r.Covered, r.Total)
+}
+
+func (d DecisionCoverage) Percent() float64 {
+	return percent(d.Covered, d.Total)
+}
+
+func percent(covered, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+
+	return float64(covered) * 100 / float64(total)
+}
+
+// Uncovered lists nodes never visited, and rules and statements never hit.
+func (r CoverageReport) Uncovered() []UncoveredItem {
+	var uncovered []UncoveredItem
+	for _, decision := range r.Decisions {
+		for _, node := range decision.Nodes {
+			item := UncoveredItem{Key: decision.Key, NodeID: node.ID, NodeName: node.Name}
+			if len(node.Rules) == 0 && len(node.Statements) == 0 {
+				if node.Hits == 0 {
+					uncovered = append(uncovered, item)
+				}
+
+				continue
+			}
+
+			for _, rule := range append(append([]RuleCoverage(nil), node.Rules...), node.Statements...) {
+				if rule.Hits == 0 {
+					item.RuleID, item.RuleIndex = rule.ID, rule.Index
+					uncovered = append(uncovered, item)
+				}
+			}
+		}
+	}
+
+	return uncovered
+}
+
+// WriteText writes a summary similar to go test -cover, followed by uncovered items.
+func (r CoverageReport) WriteText(w io.Writer) error {
+	for _, decision := range r.Decisions {
+		if _, err := fmt.Fprintf(w, "%s\tcoverage: %.1f%% (%d/%d)\n", decision.Key, decision.Percent(), decision.Covered, decision.Total); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "total\tcoverage: %.1f%% (%d/%d)\n", r.Percent(), r.Covered, r.Total); err != nil {
+		return err
+	}
+
+	for _, item := range r.Uncovered() {
+		line := fmt.Sprintf("uncovered: %s %q", item.Key, item.NodeName)
+		if item.RuleID != "" {
+			line += fmt.Sprintf(" row %d (%s)", item.RuleIndex+1, item.RuleID)
+		}
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var coverageTemplate = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Decision coverage</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { border: 1px solid #ccc; padding: 2px 8px; text-align: left; }
+.covered { background: #dfd; }
+.uncovered { background: #fdd; }
+</style>
+</head>
+<body>
+<h1>Decision coverage: {{printf "%.1f" .Percent}}% ({{.Covered}}/{{.Total}})</h1>
+{{range .Decisions}}
+<h2>{{.Key}}: {{printf "%.1f" .Percent}}% ({{.Covered}}/{{.Total}})</h2>
+<table>
+<tr><th>Node</th><th>Type</th><th>Item</th><th>Hits</th></tr>
+{{range .Nodes}}
+<tr class="{{if .Hits}}covered{{else}}uncovered{{end}}"><td>{{.Name}}</td><td>{{.Type}}</td><td></td><td>{{.Hits}}</td></tr>
+{{range .Rules}}<tr class="{{if .Hits}}covered{{else}}uncovered{{end}}"><td></td><td>rule</td><td>row {{inc .Index}} {{.ID}} {{.Description}}</td><td>{{.Hits}}</td></tr>
+{{end}}{{range .Statements}}<tr class="{{if .Hits}}covered{{else}}uncovered{{end}}"><td></td><td>statement</td><td>{{inc .Index}} {{.ID}}</td><td>{{.Hits}}</td></tr>
+{{end}}{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+func (r CoverageReport) WriteHTML(w io.Writer) error {
+	return coverageTemplate.Execute(w, r)
+}
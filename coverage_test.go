@@ -0,0 +1,120 @@
+package zen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/gorules/zen-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func tableTrace(ruleID string, index int) *json.RawMessage {
+	trace := json.RawMessage(fmt.Sprintf(`{
+		"3e3f5093-c969-4c3a-97e1-560e4b769a12": {"id": "3e3f5093-c969-4c3a-97e1-560e4b769a12", "name": "Request", "traceData": null},
+		"0624d5fd-1944-4781-92bb-e32873ce91e2": {"id": "0624d5fd-1944-4781-92bb-e32873ce91e2", "name": "Hello", "traceData": {
+			"index": %d, "reference_map": {}, "rule": {"_id": %q}
+		}}
+	}`, index, ruleID))
+	return &trace
+}
+
+func TestCoverage(t *testing.T) {
+	coverage := zen.NewCoverage()
+	content, err := readTestFile("table.json")
+	assert.NoError(t, err)
+	assert.NoError(t, coverage.Register("table.json", content))
+
+	report := coverage.Report()
+	assert.Equal(t, 0, report.Covered)
+	assert.Equal(t, 4, report.Total, "two rules plus input and output nodes")
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, coverage.Record("table.json", &zen.EvaluationResponse{Trace: tableTrace("5ZnYGPFT-N", 0)}))
+	}
+
+	report = coverage.Report()
+	assert.Equal(t, 2, report.Covered)
+	assert.InDelta(t, 50, report.Percent(), 0.001)
+
+	table := report.Decisions[0].Nodes[1]
+	assert.Equal(t, "decisionTableNode", table.Type)
+	assert.Equal(t, 3, table.Hits)
+	assert.Equal(t, []zen.RuleCoverage{{ID: "5ZnYGPFT-N", Index: 0, Hits: 3}, {ID: "pSg-vIQR5Q", Index: 1}}, table.Rules)
+
+	assert.Equal(t, []zen.UncoveredItem{
+		{Key: "table.json", NodeID: "0624d5fd-1944-4781-92bb-e32873ce91e2", NodeName: "Hello", RuleID: "pSg-vIQR5Q", RuleIndex: 1},
+		{Key: "table.json", NodeID: "e0438c6b-dee0-405e-a941-9b4c3d9c4b83", NodeName: "Response"},
+	}, report.Uncovered())
+
+	var text bytes.Buffer
+	assert.NoError(t, report.WriteText(&text))
+	assert.Equal(t, `table.json	coverage: 50.0% (2/4)
+total	coverage: 50.0% (2/4)
+uncovered: table.json "Hello" row 2 (pSg-vIQR5Q)
+uncovered: table.json "Response"
+`, text.String())
+
+	var html bytes.Buffer
+	assert.NoError(t, report.WriteHTML(&html))
+	assert.Contains(t, html.String(), "row 2 pSg-vIQR5Q")
+
+	data, err := json.Marshal(report)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"covered":2`)
+
+	coverage.Reset()
+	assert.Equal(t, 0, coverage.Report().Covered)
+}
+
+func TestCoverage_Errors(t *testing.T) {
+	coverage := zen.NewCoverage()
+	assert.Error(t, coverage.Record("table.json", &zen.EvaluationResponse{Trace: tableTrace("a", 0)}))
+	assert.Error(t, coverage.Record("table.json", &zen.EvaluationResponse{}))
+	assert.Error(t, coverage.Register("table.json", []byte(`not json`)))
+}
+
+func TestCoverage_Engine(t *testing.T) {
+	coverage := zen.NewCoverage()
+	engine := zen.NewEngine(zen.EngineConfig{Loader: coverage.WrapLoader(readTestFile)})
+	defer engine.Dispose()
+
+	response, err := engine.EvaluateWithOpts("table.json", map[string]any{"input": 15}, zen.EvaluationOptions{Trace: true})
+	assert.NoError(t, err)
+	assert.NoError(t, coverage.Record("table.json", response))
+	assert.Len(t, coverage.Report().Decisions, 1)
+
+	// without a decision cache the loader runs again, which must not reset hits
+	response, err = engine.EvaluateWithOpts("table.json", map[string]any{"input": 15}, zen.EvaluationOptions{Trace: true})
+	assert.NoError(t, err)
+	assert.NoError(t, coverage.Record("table.json", response))
+
+	table := coverage.Report().Decisions[0].Nodes[1]
+	assert.Equal(t, 2, table.Hits)
+	assert.Equal(t, 2, table.Rules[0].Hits)
+}
+
+func TestCoverage_Register(t *testing.T) {
+	coverage := zen.NewCoverage()
+	content, err := readTestFile("table.json")
+	assert.NoError(t, err)
+	assert.NoError(t, coverage.Register("table.json", content))
+	assert.NoError(t, coverage.Record("table.json", &zen.EvaluationResponse{Trace: tableTrace("pSg-vIQR5Q", 1)}))
+
+	assert.NoError(t, coverage.Register("table.json", content))
+	assert.Equal(t, 1, coverage.Report().Decisions[0].Nodes[1].Rules[1].Hits, "unchanged content keeps hits")
+
+	// removing the first rule keeps hits of the remaining one
+	var jdm map[string]any
+	assert.NoError(t, json.Unmarshal(content, &jdm))
+	table := jdm["nodes"].([]any)[1].(map[string]any)["content"].(map[string]any)
+	table["rules"] = table["rules"].([]any)[1:]
+	changed, err := json.Marshal(jdm)
+	assert.NoError(t, err)
+	assert.NoError(t, coverage.Register("table.json", changed))
+
+	node := coverage.Report().Decisions[0].Nodes[1]
+	assert.Equal(t, 1, node.Hits)
+	assert.Equal(t, []zen.RuleCoverage{{ID: "pSg-vIQR5Q", Index: 0, Hits: 1}}, node.Rules)
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/tidwall/gjson"
+	"strings"
+	"time"
 )
 
 type CustomNodeHandler func(request NodeRequest) (NodeResponse, error)
@@ -27,19 +29,19 @@ type NodeResponse struct {
 	TraceData any `json:"traceData"`
 }
 
-func wrapCustomNodeHandler(customNodeHandler CustomNodeHandler) func(cRequest *C.char) C.ZenCustomNodeResult {
+func wrapCustomNodeHandler(codec Codec, logger logger, customNodeHandler CustomNodeHandler) func(cRequest *C.char) C.ZenCustomNodeResult {
 	return func(cRequest *C.char) C.ZenCustomNodeResult {
-		strRequest := C.GoString(cRequest)
-
 		var request NodeRequest
-		if err := json.Unmarshal([]byte(strRequest), &request); err != nil {
+		if err := codec.Unmarshal(goBytes(cRequest), &request); err != nil {
 			return C.ZenCustomNodeResult{
 				content: nil,
 				error:   C.CString(err.Error()),
 			}
 		}
 
+		start := time.Now()
 		response, err := customNodeHandler(request)
+		logger.customNode(request, time.Since(start), err)
 		if err != nil {
 			return C.ZenCustomNodeResult{
 				content: nil,
@@ -47,7 +49,7 @@ func wrapCustomNodeHandler(customNodeHandler CustomNodeHandler) func(cRequest *C
 			}
 		}
 
-		cResponse, err := json.Marshal(response)
+		cResponse, err := codec.Marshal(response)
 		if err != nil {
 			return C.ZenCustomNodeResult{
 				content: nil,
@@ -56,7 +58,7 @@ func wrapCustomNodeHandler(customNodeHandler CustomNodeHandler) func(cRequest *C
 		}
 
 		return C.ZenCustomNodeResult{
-			content: C.CString(string(cResponse)),
+			content: cStringFromBytes(cResponse),
 			error:   nil,
 		}
 	}
@@ -93,3 +95,118 @@ func GetNodeField[T any](request NodeRequest, path string) (T, error) {
 
 	return RenderTemplate[T](result.Str, request.Input)
 }
+
+// GetNodeFieldSlice reads an array from the node config, rendering templates found in any of its (nested) elements.
+func GetNodeFieldSlice[T any](request NodeRequest, path string) ([]T, error) {
+	result := gjson.GetBytes(request.Node.Config, path)
+	if !result.Exists() {
+		return nil, errors.New("path does not exist")
+	}
+
+	if !result.IsArray() {
+		return nil, errors.New("path is not an array")
+	}
+
+	return renderNodeValue[[]T](result, request.Input)
+}
+
+// GetNodeFieldMap reads an object from the node config, rendering templates found in any of its (nested) values.
+func GetNodeFieldMap[T any](request NodeRequest, path string) (map[string]T, error) {
+	result := gjson.GetBytes(request.Node.Config, path)
+	if !result.Exists() {
+		return nil, errors.New("path does not exist")
+	}
+
+	if !result.IsObject() {
+		return nil, errors.New("path is not an object")
+	}
+
+	return renderNodeValue[map[string]T](result, request.Input)
+}
+
+// DecodeConfig decodes the whole node config into T, templates are rendered recursively against the node input.
+func DecodeConfig[T any](request NodeRequest) (T, error) {
+	return renderNodeValue[T](gjson.ParseBytes(request.Node.Config), request.Input)
+}
+
+// GetInputField reads a single field from the node input, no template rendering is applied.
+func GetInputField[T any](request NodeRequest, path string) (T, error) {
+	result := gjson.GetBytes(request.Input, path)
+	if !result.Exists() {
+		return *new(T), errors.New("path does not exist")
+	}
+
+	var r T
+	if err := json.Unmarshal([]byte(result.Raw), &r); err != nil {
+		return *new(T), err
+	}
+
+	return r, nil
+}
+
+// DecodeInput decodes the whole node input into T.
+func DecodeInput[T any](request NodeRequest) (T, error) {
+	var r T
+	if err := json.Unmarshal(request.Input, &r); err != nil {
+		return *new(T), err
+	}
+
+	return r, nil
+}
+
+func renderNodeValue[T any](result gjson.Result, input json.RawMessage) (T, error) {
+	rendered, err := renderTemplates(result, []byte(input))
+	if err != nil {
+		return *new(T), err
+	}
+
+	data, err := json.Marshal(rendered)
+	if err != nil {
+		return *new(T), err
+	}
+
+	var r T
+	if err := json.Unmarshal(data, &r); err != nil {
+		return *new(T), err
+	}
+
+	return r, nil
+}
+
+// renderTemplates walks the config value and renders every string containing a template, other values are kept as-is.
+func renderTemplates(result gjson.Result, input []byte) (any, error) {
+	switch {
+	case result.Type == gjson.String:
+		if !strings.Contains(result.Str, "{{") {
+			return result.Str, nil
+		}
+
+		return RenderTemplate[any](result.Str, input)
+	case result.IsArray():
+		values := make([]any, 0)
+		var err error
+		result.ForEach(func(_, value gjson.Result) bool {
+			var rendered any
+			rendered, err = renderTemplates(value, input)
+			values = append(values, rendered)
+			return err == nil
+		})
+
+		return values, err
+	case result.IsObject():
+		values := make(map[string]any)
+		var err error
+		result.ForEach(func(key, value gjson.Result) bool {
+			var rendered any
+			rendered, err = renderTemplates(value, input)
+			values[key.Str] = rendered
+			return err == nil
+		})
+
+		return values, err
+	case !result.Exists():
+		return nil, nil
+	default:
+		return json.RawMessage(result.Raw), nil
+	}
+}
@@ -0,0 +1,119 @@
+package zen_test
+
+import (
+	"encoding/json"
+	"github.com/gorules/zen-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func prepareNodeRequest() zen.NodeRequest {
+	return zen.NodeRequest{
+		Node: zen.CustomNode{
+			ID:   "node-1",
+			Name: "customNode1",
+			Kind: "sum",
+			Config: json.RawMessage(`{
+				"key": "sum",
+				"limit": 100,
+				"operands": ["{{ a + 10 }}", "{{ b + 5 }}"],
+				"labels": {"first": "{{ customer.name }}", "second": "static"},
+				"nested": {"target": {"value": "{{ a * 2 }}"}, "items": [{"value": "{{ b }}"}]}
+			}`),
+		},
+		Input: json.RawMessage(`{"a": 5, "b": 10, "customer": {"name": "John", "tags": ["vip", "new"]}}`),
+	}
+}
+
+func TestGetInputField(t *testing.T) {
+	request := prepareNodeRequest()
+
+	a, err := zen.GetInputField[int](request, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, a)
+
+	name, err := zen.GetInputField[string](request, "customer.name")
+	assert.NoError(t, err)
+	assert.Equal(t, "John", name)
+
+	tags, err := zen.GetInputField[[]string](request, "customer.tags")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vip", "new"}, tags)
+
+	_, err = zen.GetInputField[int](request, "missing")
+	assert.Error(t, err)
+
+	_, err = zen.GetInputField[int](request, "customer.name")
+	assert.Error(t, err)
+}
+
+func TestDecodeInput(t *testing.T) {
+	type input struct {
+		A        int `json:"a"`
+		B        int `json:"b"`
+		Customer struct {
+			Name string `json:"name"`
+		} `json:"customer"`
+	}
+
+	decoded, err := zen.DecodeInput[input](prepareNodeRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, decoded.A)
+	assert.Equal(t, 10, decoded.B)
+	assert.Equal(t, "John", decoded.Customer.Name)
+}
+
+func TestDecodeConfig(t *testing.T) {
+	type config struct {
+		Key      string            `json:"key"`
+		Limit    int               `json:"limit"`
+		Operands []int             `json:"operands"`
+		Labels   map[string]string `json:"labels"`
+		Nested   struct {
+			Target struct {
+				Value int `json:"value"`
+			} `json:"target"`
+			Items []struct {
+				Value int `json:"value"`
+			} `json:"items"`
+		} `json:"nested"`
+	}
+
+	decoded, err := zen.DecodeConfig[config](prepareNodeRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, "sum", decoded.Key)
+	assert.Equal(t, 100, decoded.Limit)
+	assert.Equal(t, []int{15, 15}, decoded.Operands)
+	assert.Equal(t, map[string]string{"first": "John", "second": "static"}, decoded.Labels)
+	assert.Equal(t, 10, decoded.Nested.Target.Value)
+	assert.Len(t, decoded.Nested.Items, 1)
+	assert.Equal(t, 10, decoded.Nested.Items[0].Value)
+}
+
+func TestGetNodeFieldSlice(t *testing.T) {
+	request := prepareNodeRequest()
+
+	operands, err := zen.GetNodeFieldSlice[int](request, "operands")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{15, 15}, operands)
+
+	_, err = zen.GetNodeFieldSlice[int](request, "labels")
+	assert.Error(t, err)
+
+	_, err = zen.GetNodeFieldSlice[int](request, "missing")
+	assert.Error(t, err)
+}
+
+func TestGetNodeFieldMap(t *testing.T) {
+	request := prepareNodeRequest()
+
+	labels, err := zen.GetNodeFieldMap[string](request, "labels")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"first": "John", "second": "static"}, labels)
+
+	_, err = zen.GetNodeFieldMap[string](request, "operands")
+	assert.Error(t, err)
+
+	_, err = zen.GetNodeFieldMap[string](request, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,11 @@
+//go:build zen_debug
+// +build zen_debug
+
+package zen
+
+import "log"
+
+// warnLeaked reports native resources which were garbage collected without being disposed.
+func warnLeaked(kind string) {
+	log.Printf("zen: %s was garbage collected without calling Dispose", kind)
+}
@@ -0,0 +1,133 @@
+package zen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+)
+
+// maxDecimalScale matches the maximum scale of decimal numbers used by the engine.
+const maxDecimalScale = 28
+
+var (
+	bigTwo  = big.NewInt(2)
+	bigFive = big.NewInt(5)
+)
+
+// Decimal is an arbitrary-precision number which is encoded as a JSON number without passing through float64.
+// Zero value represents 0.
+type Decimal struct {
+	rat *big.Rat
+}
+
+// NewDecimal creates Decimal from a copy of r.
+func NewDecimal(r *big.Rat) Decimal {
+	return Decimal{rat: new(big.Rat).Set(r)}
+}
+
+// NewDecimalFromInt creates Decimal from a copy of i.
+func NewDecimalFromInt(i *big.Int) Decimal {
+	return Decimal{rat: new(big.Rat).SetInt(i)}
+}
+
+// ParseDecimal parses decimal (e.g. 12.345 or 1.5e-3) or fractional (e.g. 1/3) string representation.
+func ParseDecimal(s string) (Decimal, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, errors.New("invalid decimal: " + strconv.Quote(s))
+	}
+
+	return Decimal{rat: r}, nil
+}
+
+// Rat returns a copy of underlying rational number.
+func (d Decimal) Rat() *big.Rat {
+	if d.rat == nil {
+		return new(big.Rat)
+	}
+
+	return new(big.Rat).Set(d.rat)
+}
+
+// String returns exact decimal representation, numbers without a finite one (e.g. 1/3) are rounded to 28 decimal
+// places, with halves rounded away from zero.
+func (d Decimal) String() string {
+	if d.rat == nil {
+		return "0"
+	}
+
+	if d.rat.IsInt() {
+		return d.rat.Num().String()
+	}
+
+	return d.rat.FloatString(decimalScale(d.rat.Denom()))
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts JSON numbers as well as strings holding a number, null leaves Decimal unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+
+		data = []byte(s)
+	}
+
+	parsed, err := ParseDecimal(string(data))
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+// decimalScale returns number of decimal places needed to represent 1/denom exactly, or maxDecimalScale when
+// the expansion does not terminate.
+func decimalScale(denom *big.Int) int {
+	remainder := new(big.Int).Set(denom)
+	twos, fives := 0, 0
+
+	mod := new(big.Int)
+	for {
+		quotient, m := new(big.Int).QuoRem(remainder, bigTwo, mod)
+		if m.Sign() != 0 {
+			break
+		}
+
+		remainder = quotient
+		twos++
+	}
+
+	for {
+		quotient, m := new(big.Int).QuoRem(remainder, bigFive, mod)
+		if m.Sign() != 0 {
+			break
+		}
+
+		remainder = quotient
+		fives++
+	}
+
+	scale := twos
+	if fives > scale {
+		scale = fives
+	}
+
+	if remainder.Cmp(big.NewInt(1)) != 0 {
+		return maxDecimalScale
+	}
+
+	return scale
+}
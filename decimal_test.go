@@ -0,0 +1,113 @@
+package zen_test
+
+import (
+	"encoding/json"
+	"github.com/gorules/zen-go"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func TestDecimal_MarshalJSON(t *testing.T) {
+	type TestCase struct {
+		value  string
+		output string
+	}
+
+	testCases := []TestCase{
+		{value: "0", output: "0"},
+		{value: "12", output: "12"},
+		{value: "-12.345", output: "-12.345"},
+		{value: "1.5e-3", output: "0.0015"},
+		{value: "12345678901234567890.123456789", output: "12345678901234567890.123456789"},
+		{value: "1/4", output: "0.25"},
+		{value: "1/3", output: "0.3333333333333333333333333333"},
+		{value: "2/3", output: "0.6666666666666666666666666667"},
+	}
+
+	for _, testCase := range testCases {
+		d, err := zen.ParseDecimal(testCase.value)
+		assert.NoError(t, err)
+
+		data, err := json.Marshal(map[string]any{"value": d})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"value":`+testCase.output+`}`, string(data))
+	}
+
+	data, err := json.Marshal(zen.Decimal{})
+	assert.NoError(t, err)
+	assert.Equal(t, "0", string(data))
+
+	_, err = zen.ParseDecimal("abc")
+	assert.Error(t, err)
+}
+
+func TestDecimal_UnmarshalJSON(t *testing.T) {
+	var target struct {
+		Number zen.Decimal `json:"number"`
+		String zen.Decimal `json:"string"`
+		Null   zen.Decimal `json:"null"`
+	}
+
+	err := json.Unmarshal([]byte(`{"number": 0.1000000000000000055511151231257827, "string": "19.99", "null": null}`), &target)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.1000000000000000055511151231257827", target.Number.String())
+	assert.Equal(t, 0, target.String.Rat().Cmp(big.NewRat(1999, 100)))
+	assert.Equal(t, "0", target.Null.String())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"number": "abc"}`), &target))
+	assert.Error(t, json.Unmarshal([]byte(`{"number": true}`), &target))
+}
+
+func TestJSONCodec_UseNumber(t *testing.T) {
+	var value map[string]any
+	assert.NoError(t, zen.JSONCodec{UseNumber: true}.Unmarshal([]byte(`{"amount": 12345678901234567.89}`), &value))
+	assert.Equal(t, json.Number("12345678901234567.89"), value["amount"])
+
+	assert.NoError(t, zen.JSONCodec{}.Unmarshal([]byte(`{"amount": 12345678901234567.89}`), &value))
+	assert.IsType(t, float64(0), value["amount"])
+
+	assert.Error(t, zen.JSONCodec{UseNumber: true}.Unmarshal([]byte(`{} {}`), &value))
+}
+
+func TestEngine_PreciseNumbers(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, Codec: zen.JSONCodec{UseNumber: true}})
+	defer engine.Dispose()
+
+	amount, err := zen.ParseDecimal("1234567890123456.75")
+	assert.NoError(t, err)
+
+	count, ok := new(big.Int).SetString("1234567890123456789", 10)
+	assert.True(t, ok)
+
+	output, err := engine.Evaluate("expression.json", map[string]any{
+		"numbers":   []any{amount, count, json.Number("0.1"), 5},
+		"firstName": "John",
+		"lastName":  "Doe",
+	})
+	assert.NoError(t, err)
+
+	var result map[string]any
+	assert.NoError(t, output.DecodeResult(&result))
+	assert.Equal(t, []any{amount.String(), count.String()}, numbersToStrings(result["largeNumbers"]))
+
+	var typed struct {
+		LargeNumbers []zen.Decimal `json:"largeNumbers"`
+	}
+	assert.NoError(t, output.DecodeResult(&typed))
+	assert.Len(t, typed.LargeNumbers, 2)
+	assert.Equal(t, 0, typed.LargeNumbers[0].Rat().Cmp(amount.Rat()))
+	assert.Equal(t, 0, typed.LargeNumbers[1].Rat().Cmp(new(big.Rat).SetInt(count)))
+}
+
+func numbersToStrings(value any) []any {
+	values, _ := value.([]any)
+	result := make([]any, 0, len(values))
+	for _, v := range values {
+		if n, ok := v.(json.Number); ok {
+			result = append(result, n.String())
+		}
+	}
+
+	return result
+}
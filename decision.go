@@ -3,68 +3,188 @@ package zen
 // #include "zen_engine.h"
 import "C"
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
+var liveDecisions atomic.Int64
+
+// LiveDecisions returns number of decisions not yet freed in the process, including decisions held by decision caches
+// and those recreated for per-call overrides.
+func LiveDecisions() int64 {
+	return liveDecisions.Load()
+}
+
 type decision struct {
 	decisionPtr *C.ZenDecisionStruct
+	codec       Codec
+	resource    *resource
+	// key is empty for decisions created from content
+	key          string
+	logger       logger
+	auditor      *auditor
+	auditContent auditContent
+	// overrides recreate the decision from its content in pooled scoped engines
+	overrides *scopedPool
 }
 
 // newDecision: called internally by zen_engine only, cleanup should still be fired however.
-func newDecision(decisionPtr *C.ZenDecisionStruct) Decision {
-	return decision{
-		decisionPtr: decisionPtr,
+// Decision keeps the engine alive, as native decision calls back into loader and custom node handles of the engine.
+// Content is nil when the decision was loaded by the native engine, such decisions cannot be evaluated with overrides.
+func newDecision(engine *engine, decisionPtr *C.ZenDecisionStruct, key string, auditContent auditContent, content []byte) *decision {
+	newDecision := &decision{
+		decisionPtr:  decisionPtr,
+		codec:        engine.codec,
+		key:          key,
+		logger:       engine.logger,
+		auditor:      engine.auditor,
+		auditContent: auditContent,
+		overrides:    newScopedPool(engine.config, recreateDecision(key, content, auditContent)),
+	}
+
+	engineResource := engine.resource
+	engineResource.retain()
+	liveDecisions.Add(1)
+
+	// free must not reference the decision itself, otherwise finalizer would never run
+	newDecision.resource = newResource(func() {
+		C.zen_decision_free(decisionPtr)
+		liveDecisions.Add(-1)
+		engineResource.release()
+	})
+
+	if engine.config.LeakProtection {
+		runtime.SetFinalizer(newDecision, (*decision).finalize)
+	}
+
+	return newDecision
+}
+
+// recreateDecision compiles content in a scoped engine, keeping key and audit content of the original decision.
+func recreateDecision(key string, content []byte, auditContent auditContent) func(scoped *engine) (*decision, error) {
+	return func(scoped *engine) (*decision, error) {
+		if content == nil {
+			return nil, errors.New("decision content is not available for per-call overrides")
+		}
+
+		return scoped.createDecision(key, content, auditContent)
 	}
 }
 
-func (decision decision) Evaluate(context any) (*EvaluationResponse, error) {
+func (decision *decision) Evaluate(context any) (*EvaluationResponse, error) {
 	return decision.EvaluateWithOpts(context, EvaluationOptions{})
 }
 
-func (decision decision) EvaluateWithOpts(context any, options EvaluationOptions) (*EvaluationResponse, error) {
-	jsonData, err := extractJsonFromAny(context)
+func (decision *decision) EvaluateWithOpts(context any, options EvaluationOptions) (*EvaluationResponse, error) {
+	if err := decision.resource.acquire(); err != nil {
+		return nil, err
+	}
+	defer decision.resource.release()
+
+	if options.hasOverrides() {
+		return decision.evaluateWithOverrides(context, options)
+	}
+
+	return decision.evaluate(context, options)
+}
+
+func (decision *decision) evaluateWithOverrides(context any, options EvaluationOptions) (*EvaluationResponse, error) {
+	scoped, err := decision.overrides.get(options)
 	if err != nil {
 		return nil, err
 	}
+	defer decision.overrides.put(scoped)
+
+	return scoped.decision.evaluate(context, options.withoutOverrides())
+}
 
-	cData := C.CString(string(jsonData))
-	defer C.free(unsafe.Pointer(cData))
+func (decision *decision) evaluate(context any, options EvaluationOptions) (*EvaluationResponse, error) {
+	input, err := newJsonBuffer(decision.codec, context)
+	if err != nil {
+		return nil, err
+	}
+	defer input.release()
 
+	if err := checkInputSize(options, input.bytes()); err != nil {
+		return nil, err
+	}
+
+	if decision.auditor == nil {
+		return decision.evaluateInput(input, options)
+	}
+
+	evaluatedAt := time.Now()
+	response, err := decision.evaluateInput(input, options)
+	if auditErr := decision.auditor.record(decision.key, decision.auditContent, options, input.bytes(), evaluatedAt, response, err); auditErr != nil {
+		return nil, auditErr
+	}
+
+	return response, err
+}
+
+func (decision *decision) evaluateInput(input jsonBuffer, options EvaluationOptions) (*EvaluationResponse, error) {
 	maxDepth := options.MaxDepth
 	if maxDepth == 0 {
 		maxDepth = 1
 	}
 
-	resultPtr := C.zen_decision_evaluate(decision.decisionPtr, cData, C.ZenEngineEvaluationOptions{
+	resultPtr := C.zen_decision_evaluate(decision.decisionPtr, input.cString(), C.ZenEngineEvaluationOptions{
 		trace:     C.bool(options.Trace),
 		max_depth: C.uint8_t(maxDepth),
 	})
 	if resultPtr.error > 0 {
-		var errorDetails string
-		if resultPtr.details != nil {
-			defer C.free(unsafe.Pointer(resultPtr.details))
-			errorDetails = C.GoString(resultPtr.details)
-		} else {
-			errorDetails = fmt.Sprintf("Error code: %d", resultPtr.error)
-		}
-
-		return nil, errors.New(errorDetails)
+		err := newEngineError(resultPtr.error, resultPtr.details)
+		decision.logger.evaluationFailed(decision.key, input.bytes(), err)
+		return nil, err
 	}
 
 	defer C.free(unsafe.Pointer(resultPtr.result))
-	result := C.GoString(resultPtr.result)
+	result, err := readResult(options, resultPtr.result)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEvaluationResponse(decision.codec, result)
+}
 
-	var response EvaluationResponse
-	if err := json.Unmarshal([]byte(result), &response); err != nil {
+// EvaluateBatch evaluates all contexts in parallel, failure of a single item is reported in its BatchResult.
+// With per-call overrides, a single scoped decision serves the whole batch.
+func (decision *decision) EvaluateBatch(contexts []any, options EvaluationOptions) ([]BatchResult, error) {
+	if err := decision.resource.acquire(); err != nil {
 		return nil, err
 	}
+	defer decision.resource.release()
+
+	target := decision
+	if options.hasOverrides() {
+		scoped, err := decision.overrides.get(options)
+		if err != nil {
+			return nil, err
+		}
+		defer decision.overrides.put(scoped)
+
+		target = scoped.decision
+		options = options.withoutOverrides()
+	}
+
+	return evaluateBatch(contexts, func(context any) (*EvaluationResponse, error) {
+		return target.evaluate(context, options)
+	}), nil
+}
 
-	return &response, nil
+// Dispose frees the decision once all in-flight calls have finished, subsequent calls are no-op.
+func (decision *decision) Dispose() {
+	if decision.resource.dispose() {
+		decision.overrides.close()
+	}
 }
 
-func (decision decision) Dispose() {
-	C.zen_decision_free(decision.decisionPtr)
+func (decision *decision) finalize() {
+	if decision.resource.dispose() {
+		decision.overrides.close()
+		warnLeaked("decision")
+	}
 }
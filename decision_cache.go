@@ -0,0 +1,198 @@
+package zen
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DecisionCacheConfig enables caching of decisions used by Engine.Evaluate, keyed by loader key.
+type DecisionCacheConfig struct {
+	// MaxSize limits number of cached decisions, least recently used are evicted first. Zero means no limit.
+	MaxSize int
+	// TTL expires decisions after given time since they were loaded. Zero means no expiry.
+	TTL time.Duration
+	// OnLookup is called once per cached evaluation, hit reports whether an already compiled decision was used.
+	OnLookup func(key string, hit bool)
+}
+
+type decisionCacheEntry struct {
+	key       string
+	decision  *decision
+	expiresAt time.Time
+}
+
+type decisionCacheCall struct {
+	done chan struct{}
+	err  error
+}
+
+// decisionCache holds decisions by key, every cached decision keeps a reference to the engine until it's evicted.
+type decisionCache struct {
+	config DecisionCacheConfig
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	loading    map[string]*decisionCacheCall
+	generation uint64
+	closed     bool
+}
+
+func newDecisionCache(config DecisionCacheConfig) *decisionCache {
+	return &decisionCache{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		loading: make(map[string]*decisionCacheCall),
+	}
+}
+
+// get returns acquired decision for key, caller must release it. Concurrent misses for the same key share one load.
+func (c *decisionCache) get(key string, load func(key string) (*decision, error)) (*decision, error) {
+	missed := false
+	for {
+		var stale []*decision
+
+		c.mu.Lock()
+		if element, ok := c.entries[key]; ok {
+			entry := element.Value.(*decisionCacheEntry)
+			if c.config.TTL <= 0 || time.Now().Before(entry.expiresAt) {
+				if err := entry.decision.resource.acquire(); err == nil {
+					c.lru.MoveToFront(element)
+					c.mu.Unlock()
+					c.lookup(key, !missed)
+					return entry.decision, nil
+				}
+			}
+
+			stale = append(stale, c.remove(element))
+		}
+
+		if call, ok := c.loading[key]; ok {
+			c.mu.Unlock()
+			disposeAll(stale)
+
+			missed = true
+			<-call.done
+			if call.err != nil {
+				c.lookup(key, false)
+				return nil, call.err
+			}
+
+			continue
+		}
+
+		call := &decisionCacheCall{done: make(chan struct{})}
+		c.loading[key] = call
+		generation := c.generation
+		c.mu.Unlock()
+		disposeAll(stale)
+
+		d, err := load(key)
+		call.err = err
+
+		c.mu.Lock()
+		delete(c.loading, key)
+		if err == nil {
+			// caller reference is taken before decision becomes visible to eviction
+			_ = d.resource.acquire()
+
+			if c.closed || c.generation != generation {
+				stale = append(stale, d)
+			} else {
+				stale = append(stale, c.insert(key, d)...)
+			}
+		}
+		c.mu.Unlock()
+
+		close(call.done)
+		disposeAll(stale)
+		c.lookup(key, false)
+		return d, err
+	}
+}
+
+func (c *decisionCache) lookup(key string, hit bool) {
+	if c.config.OnLookup != nil {
+		c.config.OnLookup(key, hit)
+	}
+}
+
+// insert must be called with lock held, it returns evicted decisions.
+func (c *decisionCache) insert(key string, d *decision) []*decision {
+	var evicted []*decision
+	if element, ok := c.entries[key]; ok {
+		evicted = append(evicted, c.remove(element))
+	}
+
+	entry := &decisionCacheEntry{key: key, decision: d}
+	if c.config.TTL > 0 {
+		entry.expiresAt = time.Now().Add(c.config.TTL)
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.config.MaxSize > 0 && c.lru.Len() > c.config.MaxSize {
+		evicted = append(evicted, c.remove(c.lru.Back()))
+	}
+
+	return evicted
+}
+
+// remove must be called with lock held.
+func (c *decisionCache) remove(element *list.Element) *decision {
+	entry := c.lru.Remove(element).(*decisionCacheEntry)
+	delete(c.entries, entry.key)
+
+	return entry.decision
+}
+
+func (c *decisionCache) invalidate(key string) {
+	var stale []*decision
+
+	c.mu.Lock()
+	c.generation++
+	if element, ok := c.entries[key]; ok {
+		stale = append(stale, c.remove(element))
+	}
+	c.mu.Unlock()
+
+	disposeAll(stale)
+}
+
+func (c *decisionCache) invalidateAll() {
+	c.mu.Lock()
+	stale := c.clear()
+	c.mu.Unlock()
+
+	disposeAll(stale)
+}
+
+// close releases all decisions and stops caching new ones, so that cache no longer keeps the engine alive.
+func (c *decisionCache) close() {
+	c.mu.Lock()
+	c.closed = true
+	stale := c.clear()
+	c.mu.Unlock()
+
+	disposeAll(stale)
+}
+
+// clear must be called with lock held.
+func (c *decisionCache) clear() []*decision {
+	c.generation++
+
+	stale := make([]*decision, 0, c.lru.Len())
+	for c.lru.Len() > 0 {
+		stale = append(stale, c.remove(c.lru.Back()))
+	}
+
+	return stale
+}
+
+// disposeAll is called outside of cache lock, decisions in use are freed once their evaluation finishes.
+func disposeAll(decisions []*decision) {
+	for _, d := range decisions {
+		d.Dispose()
+	}
+}
@@ -2,13 +2,16 @@ package zen
 
 // #include "zen_engine.h"
 import "C"
+import "time"
 
 type Loader func(key string) ([]byte, error)
 
-func wrapLoader(loader Loader) func(cKey *C.char) C.ZenDecisionLoaderResult {
+func wrapLoader(logger logger, loader Loader) func(cKey *C.char) C.ZenDecisionLoaderResult {
 	return func(cKey *C.char) C.ZenDecisionLoaderResult {
 		key := C.GoString(cKey)
+		start := time.Now()
 		content, err := loader(key)
+		logger.loaded(key, time.Since(start), err)
 		if err != nil {
 			return C.ZenDecisionLoaderResult{
 				content: nil,
@@ -17,7 +20,7 @@ func wrapLoader(loader Loader) func(cKey *C.char) C.ZenDecisionLoaderResult {
 		}
 
 		return C.ZenDecisionLoaderResult{
-			content: C.CString(string(content)),
+			content: cStringFromBytes(content),
 			error:   nil,
 		}
 	}
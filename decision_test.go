@@ -2,9 +2,12 @@ package zen_test
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gorules/zen-go"
 	"github.com/stretchr/testify/assert"
 	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -91,3 +94,185 @@ func TestDecision_EvaluateParallel(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestDecision_EvaluationLimits(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile})
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("table.json")
+	assert.NoError(t, err)
+	defer decision.Dispose()
+
+	_, err = decision.EvaluateWithOpts([]byte(`{"input":5}`), zen.EvaluationOptions{MaxInputSize: 5})
+	assert.ErrorIs(t, err, zen.ErrInputTooLarge)
+
+	_, err = decision.EvaluateWithOpts([]byte(`{"input":5}`), zen.EvaluationOptions{MaxOutputSize: 5})
+	assert.ErrorIs(t, err, zen.ErrOutputTooLarge)
+
+	_, err = decision.EvaluateWithOpts([]byte(`{"input":5}`), zen.EvaluationOptions{MaxInputSize: 11})
+	assert.NoError(t, err)
+}
+
+func TestDecision_EvaluateBatch(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("function.json")
+	assert.NoError(t, err)
+	defer decision.Dispose()
+
+	type responseData struct {
+		Output int `json:"output"`
+	}
+
+	contexts := make([]any, 100)
+	for i := range contexts {
+		contexts[i] = map[string]any{"input": i}
+	}
+	contexts[50] = []byte(`{"input":`)
+
+	batchEvaluator, ok := decision.(zen.BatchEvaluator)
+	assert.True(t, ok)
+
+	results, err := batchEvaluator.EvaluateBatch(contexts, zen.EvaluationOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, results, len(contexts))
+
+	for i, result := range results {
+		if i == 50 {
+			assert.Error(t, result.Err)
+			assert.Nil(t, result.Response)
+			continue
+		}
+
+		assert.NoError(t, result.Err)
+
+		var respData responseData
+		assert.NoError(t, json.Unmarshal(result.Response.Result, &respData))
+		assert.Equal(t, i*2, respData.Output)
+	}
+
+	results, err = zen.EvaluateBatch(decision, nil, zen.EvaluationOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestDecision_Dispose(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("table.json")
+	assert.NoError(t, err)
+
+	_, err = decision.Evaluate(map[string]any{"input": 5})
+	assert.NoError(t, err)
+
+	decision.Dispose()
+	decision.Dispose()
+
+	_, err = decision.Evaluate(map[string]any{"input": 5})
+	assert.ErrorIs(t, err, zen.ErrDisposed)
+
+	_, err = zen.EvaluateBatch(decision, []any{map[string]any{"input": 5}}, zen.EvaluationOptions{})
+	assert.ErrorIs(t, err, zen.ErrDisposed)
+}
+
+func TestDecision_EvaluationOverrides(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	fileData, err := readTestFile("custom-node.json")
+	assert.NoError(t, err)
+
+	created, err := engine.CreateDecision(fileData)
+	assert.NoError(t, err)
+	defer created.Dispose()
+
+	loaded, err := engine.GetDecision("custom-node.json")
+	assert.NoError(t, err)
+	defer loaded.Dispose()
+
+	for _, decision := range []zen.Decision{created, loaded} {
+		output, err := decision.EvaluateWithOpts(map[string]any{"a": 5, "b": 10}, zen.EvaluationOptions{
+			CustomNodeHandler: productNodeHandler,
+		})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"product":225}`, string(output.Result))
+
+		output, err = decision.Evaluate(map[string]any{"a": 5, "b": 10})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"sum":30}`, string(output.Result))
+	}
+}
+
+func TestDecision_EvaluateBatchOverrides(t *testing.T) {
+	var loads atomic.Int32
+	engine := zen.NewEngine(zen.EngineConfig{Loader: func(key string) ([]byte, error) {
+		loads.Add(1)
+		return readTestFile(key)
+	}})
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("table.json")
+	assert.NoError(t, err)
+	defer decision.Dispose()
+	assert.Equal(t, int32(1), loads.Load())
+
+	contexts := make([]any, 10)
+	for i := range contexts {
+		contexts[i] = map[string]any{"input": i}
+	}
+
+	results, err := zen.EvaluateBatch(decision, contexts, zen.EvaluationOptions{CustomNodeHandler: productNodeHandler})
+	assert.NoError(t, err)
+	assert.Len(t, results, len(contexts))
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+	}
+
+	assert.Equal(t, int32(1), loads.Load(), "overrides reuse content the decision was compiled from")
+}
+
+func TestDecision_OverridesReuseScopedDecision(t *testing.T) {
+	var loads atomic.Int32
+	engine := zen.NewEngine(zen.EngineConfig{Loader: func(key string) ([]byte, error) {
+		// content changes after the decision is loaded, overrides must keep evaluating the loaded one
+		if loads.Add(1) > 1 {
+			return readTestFile("custom-node.json")
+		}
+
+		return readTestFile(key)
+	}})
+	defer engine.Dispose()
+
+	live := zen.LiveDecisions()
+	decision, err := engine.GetDecision("table.json")
+	assert.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err = decision.EvaluateWithOpts(map[string]any{"input": i}, zen.EvaluationOptions{CustomNodeHandler: productNodeHandler})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), loads.Load(), "overrides do not reload the decision")
+	assert.Equal(t, live+2, zen.LiveDecisions(), "scoped decision is compiled once and reused")
+
+	decision.Dispose()
+	assert.Equal(t, live, zen.LiveDecisions())
+}
+
+func TestEvaluateBatch_Fallback(t *testing.T) {
+	decision := funcDecision{evaluate: func(input map[string]any) (string, error) {
+		if input["input"].(int) < 0 {
+			return "", errors.New("negative input")
+		}
+
+		return fmt.Sprintf(`{"output": %d}`, input["input"].(int)*2), nil
+	}}
+
+	results, err := zen.EvaluateBatch(decision, []any{map[string]any{"input": 1}, map[string]any{"input": -1}}, zen.EvaluationOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.JSONEq(t, `{"output": 2}`, string(results[0].Response.Result))
+	assert.EqualError(t, results[1].Err, "negative input")
+}
@@ -0,0 +1,137 @@
+package zen
+
+import (
+	"encoding/json"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldDiff is a single difference between two JSON documents. Path uses gjson syntax, array elements are addressed
+// by index, e.g. "items.0.price". Expected or Actual is nil when the field is missing on that side.
+type FieldDiff struct {
+	Path     string          `json:"path"`
+	Expected json.RawMessage `json:"expected,omitempty"`
+	Actual   json.RawMessage `json:"actual,omitempty"`
+}
+
+// DiffJSON compares two JSON documents and returns differing leaf fields sorted by path. Numbers are compared by
+// value, so 1 and 1.0 are equal. A difference of the whole document is reported with an empty path.
+func DiffJSON(expected, actual []byte) ([]FieldDiff, error) {
+	var expectedValue, actualValue any
+	if err := (JSONCodec{UseNumber: true}).Unmarshal(expected, &expectedValue); err != nil {
+		return nil, err
+	}
+
+	if err := (JSONCodec{UseNumber: true}).Unmarshal(actual, &actualValue); err != nil {
+		return nil, err
+	}
+
+	var diffs []FieldDiff
+	diffValues(nil, expectedValue, actualValue, &diffs)
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+
+	return diffs, nil
+}
+
+func diffValues(path []string, expected, actual any, diffs *[]FieldDiff) {
+	switch e := expected.(type) {
+	case map[string]any:
+		if a, ok := actual.(map[string]any); ok {
+			for key, value := range e {
+				fieldPath := append(path[:len(path):len(path)], escapePathKey(key))
+				if actualValue, ok := a[key]; ok {
+					diffValues(fieldPath, value, actualValue, diffs)
+				} else {
+					*diffs = append(*diffs, newFieldDiff(fieldPath, value, nil, true, false))
+				}
+			}
+
+			for key, value := range a {
+				if _, ok := e[key]; !ok {
+					fieldPath := append(path[:len(path):len(path)], escapePathKey(key))
+					*diffs = append(*diffs, newFieldDiff(fieldPath, nil, value, false, true))
+				}
+			}
+
+			return
+		}
+	case []any:
+		if a, ok := actual.([]any); ok {
+			for i := 0; i < len(e) || i < len(a); i++ {
+				elementPath := append(path[:len(path):len(path)], strconv.Itoa(i))
+				switch {
+				case i >= len(a):
+					*diffs = append(*diffs, newFieldDiff(elementPath, e[i], nil, true, false))
+				case i >= len(e):
+					*diffs = append(*diffs, newFieldDiff(elementPath, nil, a[i], false, true))
+				default:
+					diffValues(elementPath, e[i], a[i], diffs)
+				}
+			}
+
+			return
+		}
+	}
+
+	if !equalValues(expected, actual) {
+		*diffs = append(*diffs, newFieldDiff(path, expected, actual, true, true))
+	}
+}
+
+func equalValues(expected, actual any) bool {
+	switch e := expected.(type) {
+	case json.Number:
+		a, ok := actual.(json.Number)
+		if !ok {
+			return false
+		}
+
+		expectedRat, okExpected := new(big.Rat).SetString(e.String())
+		actualRat, okActual := new(big.Rat).SetString(a.String())
+		if !okExpected || !okActual {
+			return e == a
+		}
+
+		return expectedRat.Cmp(actualRat) == 0
+	case map[string]any, []any:
+		// containers of different types
+		return false
+	default:
+		return expected == actual
+	}
+}
+
+func newFieldDiff(path []string, expected, actual any, hasExpected, hasActual bool) FieldDiff {
+	diff := FieldDiff{Path: strings.Join(path, ".")}
+	if hasExpected {
+		diff.Expected, _ = json.Marshal(expected)
+	}
+
+	if hasActual {
+		diff.Actual, _ = json.Marshal(actual)
+	}
+
+	return diff
+}
+
+// escapePathKey escapes characters with special meaning in gjson paths.
+func escapePathKey(key string) string {
+	if !strings.ContainsAny(key, `.*?|#@!\`) {
+		return key
+	}
+
+	var builder strings.Builder
+	for _, r := range key {
+		if strings.ContainsRune(`.*?|#@!\`, r) {
+			builder.WriteByte('\\')
+		}
+
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,96 @@
+//go:build memory_test
+// +build memory_test
+
+package zen
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func readMemoryTestFile(key string) ([]byte, error) {
+	return os.ReadFile(path.Join("test-data", key))
+}
+
+func sumNodeHandler(request NodeRequest) (NodeResponse, error) {
+	if request.Node.Kind != "sum" {
+		return NodeResponse{}, errors.New("unknown component")
+	}
+
+	a, err := GetNodeField[int](request, "a")
+	if err != nil {
+		return NodeResponse{}, err
+	}
+
+	b, err := GetNodeField[int](request, "b")
+	if err != nil {
+		return NodeResponse{}, err
+	}
+
+	return NodeResponse{Output: map[string]any{"sum": a + b}}, nil
+}
+
+func isFreed(r *resource) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.refs == 0
+}
+
+func TestDecision_OutlivesEngine(t *testing.T) {
+	e := NewEngine(EngineConfig{Loader: readMemoryTestFile, CustomNodeHandler: sumNodeHandler})
+	engineResource := e.(*engine).resource
+
+	customNode, err := e.GetDecision("custom-node.json")
+	assert.NoError(t, err)
+
+	table, err := e.GetDecision("table.json")
+	assert.NoError(t, err)
+
+	e.Dispose()
+	assert.False(t, isFreed(engineResource))
+
+	_, err = e.Evaluate("table.json", map[string]any{"input": 5})
+	assert.ErrorIs(t, err, ErrDisposed)
+
+	// custom node calls back into the handle owned by the disposed engine
+	output, err := customNode.Evaluate(map[string]any{"a": 5, "b": 10})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"sum":30}`, string(output.Result))
+
+	customNode.Dispose()
+	assert.False(t, isFreed(engineResource))
+
+	_, err = table.Evaluate(map[string]any{"input": 5})
+	assert.NoError(t, err)
+
+	table.Dispose()
+	assert.True(t, isFreed(engineResource))
+}
+
+func TestEngine_LeakProtection(t *testing.T) {
+	var engineResource, decisionResource *resource
+
+	func() {
+		e := NewEngine(EngineConfig{Loader: readMemoryTestFile, LeakProtection: true})
+		d, err := e.GetDecision("table.json")
+		assert.NoError(t, err)
+
+		engineResource = e.(*engine).resource
+		decisionResource = d.(*decision).resource
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !isFreed(engineResource) && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.True(t, isFreed(decisionResource))
+	assert.True(t, isFreed(engineResource))
+}
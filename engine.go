@@ -3,24 +3,42 @@ package zen
 // #include "zen_engine.h"
 import "C"
 import (
-	"encoding/json"
-	"errors"
+	"bytes"
 	"fmt"
+	"log/slog"
+	"runtime"
 	"runtime/cgo"
+	"time"
 	"unsafe"
 )
 
 type engine struct {
-	loaderHandler          cgo.Handle
-	loaderHandlerIdPtr     *C.uintptr_t
-	customNodeHandler      cgo.Handle
-	customNodeHandlerIdPtr *C.uintptr_t
-	enginePtr              *C.ZenEngineStruct
+	enginePtr *C.ZenEngineStruct
+	codec     Codec
+	resource  *resource
+	cache     *decisionCache
+	logger    logger
+	auditor   *auditor
+	overrides *scopedPool
+	config    EngineConfig
 }
 
 type EngineConfig struct {
 	Loader            Loader
 	CustomNodeHandler CustomNodeHandler
+	// Codec is used for inputs, results and custom node requests and responses, defaults to JSONCodec.
+	Codec Codec
+	// LeakProtection frees engine and its decisions when they are garbage collected without Dispose.
+	// Builds with zen_debug tag log a warning for every leaked handle.
+	LeakProtection bool
+	// DecisionCache keeps decisions used by Evaluate compiled between calls, instead of loading them every time.
+	DecisionCache *DecisionCacheConfig
+	// Audit records every evaluation, see Replay.
+	Audit *AuditConfig
+	// Logger receives debug events for loads, compilation, custom node calls and evaluation errors.
+	Logger *slog.Logger
+	// RedactInput is applied to a copy of inputs before they are logged, see RedactFields. It may modify its argument.
+	RedactInput func(input []byte) []byte
 }
 
 //export zen_engine_go_loader_callback
@@ -36,122 +54,258 @@ func zen_engine_go_custom_node_callback(h C.uintptr_t, request *C.char) C.ZenCus
 }
 
 func NewEngine(config EngineConfig) Engine {
-	var newEngine = engine{}
+	config.Codec = codecOrDefault(config.Codec)
+	var newEngine = &engine{
+		codec:     config.Codec,
+		config:    config,
+		logger:    newLogger(config),
+		auditor:   newAuditor(config.Audit),
+		overrides: newScopedPool(config, nil),
+	}
 	var loaderHandlerIdPtr C.uintptr_t
 	var customNodeHandlerIdPtr C.uintptr_t
+	var handles []cgo.Handle
 
 	if config.Loader != nil {
-		newEngine.loaderHandler = cgo.NewHandle(wrapLoader(config.Loader))
-		loaderHandlerIdPtr = C.uintptr_t(newEngine.loaderHandler)
-		newEngine.loaderHandlerIdPtr = &loaderHandlerIdPtr
+		loaderHandler := cgo.NewHandle(wrapLoader(newEngine.logger, config.Loader))
+		loaderHandlerIdPtr = C.uintptr_t(loaderHandler)
+		handles = append(handles, loaderHandler)
 	}
 
 	if config.CustomNodeHandler != nil {
-		newEngine.customNodeHandler = cgo.NewHandle(wrapCustomNodeHandler(config.CustomNodeHandler))
-		customNodeHandlerIdPtr = C.uintptr_t(newEngine.customNodeHandler)
-		newEngine.customNodeHandlerIdPtr = &customNodeHandlerIdPtr
+		customNodeHandler := cgo.NewHandle(wrapCustomNodeHandler(newEngine.codec, newEngine.logger, config.CustomNodeHandler))
+		customNodeHandlerIdPtr = C.uintptr_t(customNodeHandler)
+		handles = append(handles, customNodeHandler)
+	}
+
+	if config.DecisionCache != nil {
+		newEngine.cache = newDecisionCache(*config.DecisionCache)
+	}
+
+	enginePtr := C.zen_engine_new_golang(&loaderHandlerIdPtr, &customNodeHandlerIdPtr)
+	newEngine.enginePtr = enginePtr
+	// free must not reference the engine itself, otherwise finalizer would never run
+	newEngine.resource = newResource(func() {
+		C.zen_engine_free(enginePtr)
+
+		for _, handle := range handles {
+			handle.Delete()
+		}
+	})
+
+	if config.LeakProtection {
+		runtime.SetFinalizer(newEngine, (*engine).finalize)
 	}
 
-	newEngine.enginePtr = C.zen_engine_new_golang(&loaderHandlerIdPtr, &customNodeHandlerIdPtr)
 	return newEngine
 }
 
-func (engine engine) Evaluate(key string, context any) (*EvaluationResponse, error) {
+func (engine *engine) Evaluate(key string, context any) (*EvaluationResponse, error) {
 	return engine.EvaluateWithOpts(key, context, EvaluationOptions{})
 }
 
-func (engine engine) EvaluateWithOpts(key string, context any, options EvaluationOptions) (*EvaluationResponse, error) {
-	jsonData, err := extractJsonFromAny(context)
+func (engine *engine) EvaluateWithOpts(key string, context any, options EvaluationOptions) (*EvaluationResponse, error) {
+	if err := engine.resource.acquire(); err != nil {
+		return nil, err
+	}
+	defer engine.resource.release()
+
+	if options.hasOverrides() {
+		scoped, err := engine.overrides.get(options)
+		if err != nil {
+			return nil, err
+		}
+		defer engine.overrides.put(scoped)
+
+		return scoped.engine.EvaluateWithOpts(key, context, options.withoutOverrides())
+	}
+
+	if engine.cache != nil {
+		decision, err := engine.cache.get(key, engine.getDecision)
+		if err != nil {
+			return nil, err
+		}
+		defer decision.resource.release()
+
+		return decision.evaluate(context, options)
+	}
+
+	input, err := newJsonBuffer(engine.codec, context)
 	if err != nil {
 		return nil, err
 	}
+	defer input.release()
+
+	if err := checkInputSize(options, input.bytes()); err != nil {
+		return nil, err
+	}
+
+	if engine.auditor == nil {
+		return engine.evaluate(key, input, options)
+	}
+
+	evaluatedAt := time.Now()
+	response, content, err := engine.evaluateLoaded(key, input, options)
+	if auditErr := engine.auditor.record(key, content, options, input.bytes(), evaluatedAt, response, err); auditErr != nil {
+		return nil, auditErr
+	}
+
+	return response, err
+}
 
+func (engine *engine) evaluate(key string, input jsonBuffer, options EvaluationOptions) (*EvaluationResponse, error) {
 	cKey := C.CString(key)
 	defer C.free(unsafe.Pointer(cKey))
 
-	cData := C.CString(string(jsonData))
-	defer C.free(unsafe.Pointer(cData))
-
 	maxDepth := options.MaxDepth
 	if maxDepth == 0 {
 		maxDepth = 1
 	}
 
-	resultPtr := C.zen_engine_evaluate(engine.enginePtr, cKey, cData, C.ZenEngineEvaluationOptions{
+	resultPtr := C.zen_engine_evaluate(engine.enginePtr, cKey, input.cString(), C.ZenEngineEvaluationOptions{
 		trace:     C.bool(options.Trace),
 		max_depth: C.uint8_t(maxDepth),
 	})
 	if resultPtr.error > 0 {
-		var errorDetails string
-		if resultPtr.details != nil {
-			defer C.free(unsafe.Pointer(resultPtr.details))
-			errorDetails = C.GoString(resultPtr.details)
-		} else {
-			errorDetails = fmt.Sprintf("Error code: %d", resultPtr.error)
-		}
-
-		return nil, errors.New(errorDetails)
+		err := newEngineError(resultPtr.error, resultPtr.details)
+		engine.logger.evaluationFailed(key, input.bytes(), err)
+		return nil, err
 	}
 
 	defer C.free(unsafe.Pointer(resultPtr.result))
-	result := C.GoString(resultPtr.result)
+	result, err := readResult(options, resultPtr.result)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEvaluationResponse(engine.codec, result)
+}
+
+// evaluateLoaded compiles the decision from freshly loaded content, so that the audit record carries the hash of the
+// content this evaluation was served from.
+func (engine *engine) evaluateLoaded(key string, input jsonBuffer, options EvaluationOptions) (*EvaluationResponse, auditContent, error) {
+	decision, err := engine.getDecision(key)
+	if err != nil {
+		engine.logger.evaluationFailed(key, input.bytes(), err)
+		return nil, auditContent{}, err
+	}
+	defer decision.Dispose()
+
+	response, err := decision.evaluateInput(input, options)
+	return response, decision.auditContent, err
+}
 
-	var response EvaluationResponse
-	if err := json.Unmarshal([]byte(result), &response); err != nil {
+func (engine *engine) GetDecision(key string) (Decision, error) {
+	if err := engine.resource.acquire(); err != nil {
+		return nil, err
+	}
+	defer engine.resource.release()
+
+	decision, err := engine.getDecision(key)
+	if err != nil {
 		return nil, err
 	}
 
-	return &response, nil
+	return decision, nil
 }
 
-func (engine engine) GetDecision(key string) (Decision, error) {
+// getDecision expects caller to hold a reference to the engine. Content is loaded here rather than by the native
+// engine, so that the decision keeps the exact bytes it was compiled from.
+func (engine *engine) getDecision(key string) (*decision, error) {
+	if engine.config.Loader == nil {
+		return engine.getNativeDecision(key)
+	}
+
+	start := time.Now()
+	content, err := engine.config.Loader(key)
+	engine.logger.loaded(key, time.Since(start), err)
+	if err != nil {
+		return nil, fmt.Errorf("decision %q: %w", key, err)
+	}
+
+	return engine.createDecision(key, content, engine.auditor.content(content))
+}
+
+// getNativeDecision leaves loading to the native engine, which reports the missing loader.
+func (engine *engine) getNativeDecision(key string) (*decision, error) {
 	cKey := C.CString(key)
 	defer C.free(unsafe.Pointer(cKey))
 
 	decisionPtr := C.zen_engine_get_decision(engine.enginePtr, cKey)
 	if decisionPtr.error > 0 {
-		var errorDetails string
-		if decisionPtr.details != nil {
-			defer C.free(unsafe.Pointer(decisionPtr.details))
-			errorDetails = C.GoString(decisionPtr.details)
-		} else {
-			errorDetails = fmt.Sprintf("Error code: %d", decisionPtr.error)
-		}
+		err := newEngineError(decisionPtr.error, decisionPtr.details)
+		engine.logger.compiled(key, err)
+		return nil, err
+	}
+
+	engine.logger.compiled(key, nil)
+	return newDecision(engine, decisionPtr.result, key, auditContent{}, nil), nil
+}
 
-		return nil, errors.New(errorDetails)
+func (engine *engine) CreateDecision(data []byte) (Decision, error) {
+	if err := engine.resource.acquire(); err != nil {
+		return nil, err
 	}
+	defer engine.resource.release()
 
-	return newDecision(decisionPtr.result), nil
+	decision, err := engine.createDecision("", data, engine.auditor.content(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return decision, nil
 }
 
-func (engine engine) CreateDecision(data []byte) (Decision, error) {
-	cData := C.CString(string(data))
-	defer C.free(unsafe.Pointer(cData))
+// createDecision expects caller to hold a reference to the engine. Key and auditContent label logs and audit records,
+// decisions recreated for per-call overrides keep those of the original decision.
+func (engine *engine) createDecision(key string, data []byte, auditContent auditContent) (*decision, error) {
+	cContent := cStringFromBytes(data)
+	defer C.free(unsafe.Pointer(cContent))
 
-	decisionPtr := C.zen_engine_create_decision(engine.enginePtr, cData)
+	decisionPtr := C.zen_engine_create_decision(engine.enginePtr, cContent)
 	if decisionPtr.error > 0 {
-		var errorDetails string
-		if decisionPtr.details != nil {
-			defer C.free(unsafe.Pointer(decisionPtr.details))
-			errorDetails = C.GoString(decisionPtr.details)
-		} else {
-			errorDetails = fmt.Sprintf("Error code: %d", decisionPtr.error)
-		}
+		err := newEngineError(decisionPtr.error, decisionPtr.details)
+		engine.logger.compiled(key, err)
+		return nil, err
+	}
 
-		return nil, errors.New(errorDetails)
+	engine.logger.compiled(key, nil)
+	return newDecision(engine, decisionPtr.result, key, auditContent, bytes.Clone(data)), nil
+}
+
+// Invalidate removes decision from the cache, next evaluation of key loads it again.
+func (engine *engine) Invalidate(key string) {
+	if engine.cache != nil {
+		engine.cache.invalidate(key)
 	}
+}
 
-	return newDecision(decisionPtr.result), nil
+// InvalidateAll removes all decisions from the cache.
+func (engine *engine) InvalidateAll() {
+	if engine.cache != nil {
+		engine.cache.invalidateAll()
+	}
 }
 
-func (engine engine) Dispose() {
-	C.zen_engine_free(engine.enginePtr)
+// Dispose frees the engine once all in-flight calls have finished and all decisions created by it have been
+// disposed, subsequent calls are no-op.
+func (engine *engine) Dispose() {
+	if engine.resource.dispose() {
+		engine.close()
+	}
+}
 
-	if engine.loaderHandlerIdPtr != nil {
-		engine.loaderHandler.Delete()
+func (engine *engine) finalize() {
+	if engine.resource.dispose() {
+		engine.close()
+		warnLeaked("engine")
 	}
+}
 
-	if engine.customNodeHandlerIdPtr != nil {
-		engine.customNodeHandler.Delete()
+// close releases decisions and scoped engines kept by the engine, so that they no longer keep it alive.
+func (engine *engine) close() {
+	engine.overrides.close()
+	if engine.cache != nil {
+		engine.cache.close()
 	}
 }
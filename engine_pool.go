@@ -0,0 +1,106 @@
+package zen
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// ShardStats are counters of a single engine within EnginePool.
+type ShardStats struct {
+	Evaluations uint64
+	Errors      uint64
+	InFlight    int64
+}
+
+type engineShard struct {
+	engine      *engine
+	evaluations atomic.Uint64
+	errors      atomic.Uint64
+	inFlight    atomic.Int64
+}
+
+// EnginePool spreads calls across multiple engines sharing one configuration, it implements Engine.
+// Loader and custom node handler are invoked concurrently from all shards and must be safe for concurrent use.
+type EnginePool struct {
+	shards []*engineShard
+	next   atomic.Uint64
+}
+
+// NewEnginePool creates size engines from config, size of zero or less defaults to GOMAXPROCS.
+func NewEnginePool(config EngineConfig, size int) *EnginePool {
+	if size <= 0 {
+		size = runtime.GOMAXPROCS(0)
+	}
+
+	pool := &EnginePool{shards: make([]*engineShard, size)}
+	for i := range pool.shards {
+		pool.shards[i] = &engineShard{engine: NewEngine(config).(*engine)}
+	}
+
+	return pool
+}
+
+// shard picks engines in round-robin order.
+func (pool *EnginePool) shard() *engineShard {
+	return pool.shards[(pool.next.Add(1)-1)%uint64(len(pool.shards))]
+}
+
+func (pool *EnginePool) Evaluate(key string, context any) (*EvaluationResponse, error) {
+	return pool.EvaluateWithOpts(key, context, EvaluationOptions{})
+}
+
+func (pool *EnginePool) EvaluateWithOpts(key string, context any, options EvaluationOptions) (*EvaluationResponse, error) {
+	shard := pool.shard()
+	shard.inFlight.Add(1)
+	defer shard.inFlight.Add(-1)
+
+	response, err := shard.engine.EvaluateWithOpts(key, context, options)
+	shard.evaluations.Add(1)
+	if err != nil {
+		shard.errors.Add(1)
+	}
+
+	return response, err
+}
+
+// GetDecision loads decision using one of the engines, decision keeps that engine alive until it's disposed.
+func (pool *EnginePool) GetDecision(key string) (Decision, error) {
+	return pool.shard().engine.GetDecision(key)
+}
+
+// CreateDecision creates decision using one of the engines, decision keeps that engine alive until it's disposed.
+func (pool *EnginePool) CreateDecision(data []byte) (Decision, error) {
+	return pool.shard().engine.CreateDecision(data)
+}
+
+func (pool *EnginePool) Invalidate(key string) {
+	for _, shard := range pool.shards {
+		shard.engine.Invalidate(key)
+	}
+}
+
+func (pool *EnginePool) InvalidateAll() {
+	for _, shard := range pool.shards {
+		shard.engine.InvalidateAll()
+	}
+}
+
+func (pool *EnginePool) Dispose() {
+	for _, shard := range pool.shards {
+		shard.engine.Dispose()
+	}
+}
+
+// Stats returns counters of every engine in the pool, in shard order.
+func (pool *EnginePool) Stats() []ShardStats {
+	stats := make([]ShardStats, len(pool.shards))
+	for i, shard := range pool.shards {
+		stats[i] = ShardStats{
+			Evaluations: shard.evaluations.Load(),
+			Errors:      shard.errors.Load(),
+			InFlight:    shard.inFlight.Load(),
+		}
+	}
+
+	return stats
+}
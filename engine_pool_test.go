@@ -0,0 +1,79 @@
+package zen_test
+
+import (
+	"encoding/json"
+	"github.com/gorules/zen-go"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestEnginePool_Evaluate(t *testing.T) {
+	var pool zen.Engine = zen.NewEnginePool(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler}, 4)
+	defer pool.Dispose()
+
+	testData := prepareEvaluationTestData()
+	for _, data := range testData {
+		output, err := pool.Evaluate(data.file, []byte(data.inputJson))
+		assert.NoError(t, err)
+		assert.JSONEq(t, data.outputJson, string(output.Result))
+
+		decision, err := pool.GetDecision(data.file)
+		assert.NoError(t, err)
+
+		output, err = decision.Evaluate([]byte(data.inputJson))
+		assert.NoError(t, err)
+		assert.JSONEq(t, data.outputJson, string(output.Result))
+		decision.Dispose()
+	}
+}
+
+func TestEnginePool_EvaluateParallel(t *testing.T) {
+	pool := zen.NewEnginePool(zen.EngineConfig{
+		Loader:            readTestFile,
+		CustomNodeHandler: customNodeHandler,
+		DecisionCache:     &zen.DecisionCacheConfig{},
+	}, 4)
+	defer pool.Dispose()
+
+	type responseData struct {
+		Output int `json:"output"`
+	}
+
+	const goroutines = 2000
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		current := i
+		go func() {
+			defer wg.Done()
+
+			resp, err := pool.Evaluate("function.json", map[string]any{"input": current})
+			assert.NoError(t, err)
+
+			var respData responseData
+			assert.NoError(t, json.Unmarshal(resp.Result, &respData))
+			assert.Equal(t, current*2, respData.Output)
+		}()
+	}
+
+	wg.Wait()
+
+	_, err := pool.Evaluate("missing.json", nil)
+	assert.Error(t, err)
+
+	stats := pool.Stats()
+	assert.Len(t, stats, 4)
+
+	var evaluations, errors uint64
+	for _, shard := range stats {
+		assert.Equal(t, int64(0), shard.InFlight)
+		assert.Equal(t, uint64(goroutines/4), shard.Evaluations-shard.Errors, "round-robin spreads load evenly")
+		evaluations += shard.Evaluations
+		errors += shard.Errors
+	}
+
+	assert.Equal(t, uint64(goroutines+1), evaluations)
+	assert.Equal(t, uint64(1), errors)
+}
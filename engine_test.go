@@ -1,13 +1,18 @@
 package zen_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"log/slog"
 	"os"
 	"path"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gorules/zen-go"
 )
@@ -213,3 +218,422 @@ func TestEngine_EvaluateParallel(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestNodeError_FunctionTimeout(t *testing.T) {
+	for source, timeout := range map[string]bool{
+		"InternalError: interrupted":                  true,
+		"InternalError: interrupted\n    at <eval>:3": true,
+		"interrupted":        true,
+		"Error: interrupted": false,
+		"Error: request was interrupted by the client": false,
+		"": false,
+	} {
+		assert.Equal(t, timeout, errors.Is(&zen.NodeError{Source: source}, zen.ErrFunctionTimeout), source)
+	}
+}
+
+func TestEngine_FunctionTimeout(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile})
+	defer engine.Dispose()
+
+	_, err := engine.Evaluate("function-timeout.json", map[string]any{"input": 1})
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, zen.ErrFunctionTimeout)
+
+	var nodeErr *zen.NodeError
+	assert.ErrorAs(t, err, &nodeErr)
+	assert.Equal(t, "8f3c7a2e-5a51-4c4e-9d0b-2f6a3b1c9e47", nodeErr.NodeID)
+}
+
+func TestEngine_EvaluationLimits(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile})
+	defer engine.Dispose()
+
+	_, err := engine.EvaluateWithOpts("table.json", map[string]any{"input": 5}, zen.EvaluationOptions{MaxInputSize: 5})
+	assert.ErrorIs(t, err, zen.ErrInputTooLarge)
+
+	_, err = engine.EvaluateWithOpts("table.json", map[string]any{"input": 5}, zen.EvaluationOptions{MaxOutputSize: 5})
+	assert.ErrorIs(t, err, zen.ErrOutputTooLarge)
+
+	_, err = engine.EvaluateWithOpts("table.json", map[string]any{"input": 5}, zen.EvaluationOptions{
+		MaxInputSize:  1024,
+		MaxOutputSize: 1024,
+	})
+	assert.NoError(t, err)
+
+	_, err = engine.EvaluateWithOpts("recursive.json", map[string]any{"input": 5}, zen.EvaluationOptions{MaxDepth: 3})
+	assert.ErrorIs(t, err, zen.ErrDepthLimitExceeded)
+}
+
+type countingCodec struct {
+	marshal   atomic.Int64
+	unmarshal atomic.Int64
+}
+
+func (c *countingCodec) Marshal(v any) ([]byte, error) {
+	c.marshal.Add(1)
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshal.Add(1)
+	return json.Unmarshal(data, v)
+}
+
+func TestEngine_Codec(t *testing.T) {
+	codec := &countingCodec{}
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler, Codec: codec})
+	defer engine.Dispose()
+
+	output, err := engine.Evaluate("custom-node.json", map[string]any{"a": 5, "b": 10, "c": 15})
+	assert.NoError(t, err)
+
+	// input and custom node response are encoded, custom node request is decoded
+	assert.Equal(t, int64(2), codec.marshal.Load())
+	assert.Equal(t, int64(1), codec.unmarshal.Load())
+
+	var result map[string]int
+	assert.NoError(t, output.DecodeResult(&result))
+	assert.Equal(t, map[string]int{"sum": 30}, result)
+	assert.Equal(t, int64(2), codec.unmarshal.Load())
+
+	decision, err := engine.GetDecision("table.json")
+	assert.NoError(t, err)
+	defer decision.Dispose()
+
+	output, err = decision.Evaluate(map[string]any{"input": 15})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), codec.marshal.Load())
+
+	assert.NoError(t, output.DecodeResult(&result))
+	assert.Equal(t, map[string]int{"output": 10}, result)
+	assert.Equal(t, int64(3), codec.unmarshal.Load())
+}
+
+func TestEngine_Dispose(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+
+	_, err := engine.Evaluate("table.json", map[string]any{"input": 5})
+	assert.NoError(t, err)
+
+	engine.Dispose()
+	engine.Dispose()
+
+	_, err = engine.Evaluate("table.json", map[string]any{"input": 5})
+	assert.ErrorIs(t, err, zen.ErrDisposed)
+
+	_, err = engine.GetDecision("table.json")
+	assert.ErrorIs(t, err, zen.ErrDisposed)
+
+	fileData, err := readTestFile("table.json")
+	assert.NoError(t, err)
+
+	_, err = engine.CreateDecision(fileData)
+	assert.ErrorIs(t, err, zen.ErrDisposed)
+}
+
+func TestEngine_LiveDecisions(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile})
+	defer engine.Dispose()
+
+	live := zen.LiveDecisions()
+	decision, err := engine.GetDecision("table.json")
+	assert.NoError(t, err)
+	assert.Equal(t, live+1, zen.LiveDecisions())
+
+	decision.Dispose()
+	assert.Equal(t, live, zen.LiveDecisions())
+}
+
+func TestEngine_DisposeParallel(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+
+			_, err := engine.Evaluate("function.json", map[string]any{"input": 1})
+			if err != nil {
+				assert.ErrorIs(t, err, zen.ErrDisposed)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			engine.Dispose()
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestEngine_DecisionCache(t *testing.T) {
+	var loads atomic.Int64
+	engine := zen.NewEngine(zen.EngineConfig{
+		Loader: func(key string) ([]byte, error) {
+			loads.Add(1)
+			return readTestFile(key)
+		},
+		CustomNodeHandler: customNodeHandler,
+		DecisionCache:     &zen.DecisionCacheConfig{MaxSize: 2},
+	})
+	defer engine.Dispose()
+
+	cache, ok := engine.(zen.Invalidator)
+	assert.True(t, ok)
+
+	testData := prepareEvaluationTestData()
+	for i := 0; i < 3; i++ {
+		output, err := engine.Evaluate("table.json", []byte(testData["table > 10"].inputJson))
+		assert.NoError(t, err)
+		assert.JSONEq(t, testData["table > 10"].outputJson, string(output.Result))
+	}
+	assert.Equal(t, int64(1), loads.Load())
+
+	cache.Invalidate("table.json")
+	_, err := engine.Evaluate("table.json", []byte(testData["table > 10"].inputJson))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), loads.Load())
+
+	// least recently used table.json is evicted once the third key is cached
+	_, err = engine.Evaluate("function.json", []byte(testData["function = 1"].inputJson))
+	assert.NoError(t, err)
+	_, err = engine.Evaluate("expression.json", []byte(testData["expression"].inputJson))
+	assert.NoError(t, err)
+	_, err = engine.Evaluate("function.json", []byte(testData["function = 1"].inputJson))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), loads.Load())
+
+	_, err = engine.Evaluate("table.json", []byte(testData["table > 10"].inputJson))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), loads.Load())
+
+	cache.InvalidateAll()
+	_, err = engine.Evaluate("function.json", []byte(testData["function = 1"].inputJson))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), loads.Load())
+
+	_, err = engine.Evaluate("missing.json", nil)
+	assert.Error(t, err)
+	_, err = engine.Evaluate("missing.json", nil)
+	assert.Error(t, err)
+	assert.Equal(t, int64(8), loads.Load(), "errors are not cached")
+}
+
+func TestEngine_DecisionCacheTTL(t *testing.T) {
+	var loads, hits, misses atomic.Int64
+	engine := zen.NewEngine(zen.EngineConfig{
+		Loader: func(key string) ([]byte, error) {
+			loads.Add(1)
+			return readTestFile(key)
+		},
+		DecisionCache: &zen.DecisionCacheConfig{
+			TTL: 50 * time.Millisecond,
+			OnLookup: func(key string, hit bool) {
+				if hit {
+					hits.Add(1)
+				} else {
+					misses.Add(1)
+				}
+			},
+		},
+	})
+	defer engine.Dispose()
+
+	for i := 0; i < 2; i++ {
+		_, err := engine.Evaluate("table.json", map[string]any{"input": 5})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int64(1), loads.Load())
+
+	time.Sleep(100 * time.Millisecond)
+	_, err := engine.Evaluate("table.json", map[string]any{"input": 5})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), loads.Load())
+	assert.Equal(t, int64(1), hits.Load())
+	assert.Equal(t, int64(2), misses.Load())
+}
+
+func TestEngine_DecisionCacheParallel(t *testing.T) {
+	var loads atomic.Int64
+	engine := zen.NewEngine(zen.EngineConfig{
+		Loader: func(key string) ([]byte, error) {
+			loads.Add(1)
+			return readTestFile(key)
+		},
+		CustomNodeHandler: customNodeHandler,
+		DecisionCache:     &zen.DecisionCacheConfig{},
+	})
+	defer engine.Dispose()
+
+	type responseData struct {
+		Output int `json:"output"`
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		current := i
+		go func() {
+			defer wg.Done()
+
+			if current%10 == 0 {
+				engine.(zen.Invalidator).Invalidate("function.json")
+			}
+
+			resp, err := engine.Evaluate("function.json", map[string]any{"input": current})
+			assert.NoError(t, err)
+
+			var respData responseData
+			assert.NoError(t, json.Unmarshal(resp.Result, &respData))
+			assert.Equal(t, current*2, respData.Output)
+		}()
+	}
+
+	wg.Wait()
+	assert.LessOrEqual(t, loads.Load(), int64(11))
+}
+
+func productNodeHandler(request zen.NodeRequest) (zen.NodeResponse, error) {
+	a, err := zen.GetNodeField[int](request, "a")
+	if err != nil {
+		return zen.NodeResponse{}, err
+	}
+
+	b, err := zen.GetNodeField[int](request, "b")
+	if err != nil {
+		return zen.NodeResponse{}, err
+	}
+
+	return zen.NodeResponse{Output: map[string]any{"product": a * b}}, nil
+}
+
+func TestEngine_EvaluationOverrides(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	output, err := engine.EvaluateWithOpts("custom-node.json", map[string]any{"a": 5, "b": 10}, zen.EvaluationOptions{
+		CustomNodeHandler: productNodeHandler,
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"product":225}`, string(output.Result))
+
+	var draftLoads int
+	draftLoader := func(key string) ([]byte, error) {
+		draftLoads++
+		if key == "draft.json" {
+			return readTestFile("table.json")
+		}
+
+		return readTestFile(key)
+	}
+
+	output, err = engine.EvaluateWithOpts("draft.json", map[string]any{"input": 15}, zen.EvaluationOptions{Loader: draftLoader})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"output":10}`, string(output.Result))
+	assert.Equal(t, 1, draftLoads)
+
+	// defaults are used again once overrides are gone
+	_, err = engine.Evaluate("draft.json", map[string]any{"input": 15})
+	assert.Error(t, err)
+
+	output, err = engine.Evaluate("custom-node.json", map[string]any{"a": 5, "b": 10})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"sum":30}`, string(output.Result))
+}
+
+func TestEngine_EvaluationOverridesParallel(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile})
+	defer engine.Dispose()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// scoped engines are shared between calls, but only the loader of this call may serve it
+			var loads atomic.Int32
+			loader := func(key string) ([]byte, error) {
+				loads.Add(1)
+				return readTestFile("table.json")
+			}
+
+			for j := 0; j < 5; j++ {
+				_, err := engine.EvaluateWithOpts("draft.json", map[string]any{"input": 15}, zen.EvaluationOptions{Loader: loader})
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, int32(5), loads.Load())
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestEngine_Logger(t *testing.T) {
+	var logs bytes.Buffer
+	engine := zen.NewEngine(zen.EngineConfig{
+		Loader:      readTestFile,
+		Logger:      slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		RedactInput: zen.RedactFields("applicant.ssn"),
+	})
+	defer engine.Dispose()
+
+	_, err := engine.Evaluate("missing.json", map[string]any{"applicant": map[string]any{"name": "John", "ssn": "123-45-6789"}})
+	assert.Error(t, err)
+
+	decision, err := engine.GetDecision("table.json")
+	assert.NoError(t, err)
+	decision.Dispose()
+
+	events := make(map[string]map[string]any)
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var event map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		events[event["msg"].(string)] = event
+	}
+
+	assert.Equal(t, "missing.json", events["decision load failed"]["key"])
+	assert.Equal(t, "missing.json", events["evaluation failed"]["key"])
+	assert.JSONEq(t, `{"applicant":{"name":"John","ssn":"[REDACTED]"}}`, events["evaluation failed"]["input"].(string))
+	assert.Equal(t, "table.json", events["decision loaded"]["key"])
+	assert.Equal(t, "table.json", events["decision compiled"]["key"])
+}
+
+func TestEngine_RedactInputInPlace(t *testing.T) {
+	var logs, records bytes.Buffer
+	engine := zen.NewEngine(zen.EngineConfig{
+		Loader: readTestFile,
+		Logger: slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		// overwrites every byte of its argument
+		RedactInput: func(input []byte) []byte {
+			for i := range input {
+				input[i] = 'x'
+			}
+
+			return []byte(`{}`)
+		},
+		Audit: &zen.AuditConfig{Sink: zen.NewWriterAuditSink(&records)},
+	})
+	defer engine.Dispose()
+
+	_, err := engine.Evaluate("missing.json", map[string]any{"ssn": "123-45-6789"})
+	assert.Error(t, err)
+	assert.Contains(t, logs.String(), "evaluation failed")
+
+	read, err := zen.ReadAuditRecords(&records)
+	assert.NoError(t, err)
+	if assert.Len(t, read, 1) {
+		assert.JSONEq(t, `{"ssn":"123-45-6789"}`, string(read[0].Input), "audit keeps input unchanged by the hook")
+	}
+}
+
+func TestRedactFields(t *testing.T) {
+	redact := zen.RedactFields("applicants.ssn", "card.number", "missing.field")
+
+	redacted := redact([]byte(`{"applicants":[{"ssn":"1","age":30},{"ssn":"2"}],"card":{"number":4111111111111111},"amount":1.10}`))
+	assert.JSONEq(t, `{"applicants":[{"ssn":"[REDACTED]","age":30},{"ssn":"[REDACTED]"}],"card":{"number":"[REDACTED]"},"amount":1.10}`, string(redacted))
+	assert.Equal(t, `"[REDACTED]"`, string(redact([]byte(`not json`))))
+}
@@ -0,0 +1,96 @@
+package zen
+
+// #include "zen_engine.h"
+import "C"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// ErrFunctionTimeout is wrapped by NodeError when a function node exceeds its execution time limit.
+var ErrFunctionTimeout = errors.New("function node timed out")
+
+// NodeError is returned when evaluation fails inside a graph node, NodeID identifies the offending node.
+type NodeError struct {
+	NodeID string
+	Source string
+	Trace  *json.RawMessage
+
+	details string
+}
+
+func (e *NodeError) Error() string {
+	return e.details
+}
+
+// Unwrap reports ErrFunctionTimeout when Source starts with the error QuickJS raises once the deadline is exceeded.
+// This is a heuristic, as the engine reports no distinct error code: a function throwing exactly "interrupted" matches too.
+func (e *NodeError) Unwrap() error {
+	if isInterrupted(e.Source) {
+		return ErrFunctionTimeout
+	}
+
+	return nil
+}
+
+// isInterrupted matches the uncatchable "InternalError: interrupted", with or without the error name.
+func isInterrupted(source string) bool {
+	line, _, _ := strings.Cut(strings.TrimSpace(source), "\n")
+	line = strings.TrimSpace(line)
+	return line == "interrupted" || line == "InternalError: interrupted"
+}
+
+// engineError keeps the details reported by the engine as message while matching a sentinel error.
+type engineError struct {
+	details string
+	err     error
+}
+
+func (e *engineError) Error() string {
+	return e.details
+}
+
+func (e *engineError) Unwrap() error {
+	return e.err
+}
+
+type engineErrorDetails struct {
+	Type   string           `json:"type"`
+	NodeID string           `json:"nodeId"`
+	Source string           `json:"source"`
+	Trace  *json.RawMessage `json:"trace"`
+}
+
+// newEngineError converts error code and details returned by the native engine, details are freed.
+func newEngineError(code C.uint8_t, details *C.char) error {
+	if details == nil {
+		return fmt.Errorf("Error code: %d", code)
+	}
+
+	defer C.free(unsafe.Pointer(details))
+	return parseEngineError(C.GoString(details))
+}
+
+func parseEngineError(details string) error {
+	var payload engineErrorDetails
+	if err := json.Unmarshal([]byte(details), &payload); err != nil {
+		return errors.New(details)
+	}
+
+	switch payload.Type {
+	case "NodeError":
+		return &NodeError{
+			NodeID:  payload.NodeID,
+			Source:  payload.Source,
+			Trace:   payload.Trace,
+			details: details,
+		}
+	case "DepthLimitExceeded":
+		return &engineError{details: details, err: ErrDepthLimitExceeded}
+	default:
+		return errors.New(details)
+	}
+}
@@ -0,0 +1,366 @@
+package zen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// NodeKind is inferred by Explain from the shape of node trace data, as trace does not carry node types.
+type NodeKind string
+
+const (
+	NodeKindDecisionTable NodeKind = "decisionTable"
+	NodeKindSwitch        NodeKind = "switch"
+	NodeKindExpression    NodeKind = "expression"
+	NodeKindFunction      NodeKind = "function"
+	// NodeKindOther covers input, output, decision and custom nodes, which cannot be told apart by their trace.
+	NodeKindOther NodeKind = "other"
+)
+
+// Explanation describes how a decision reached its result, built from the evaluation trace by Explain.
+type Explanation struct {
+	Performance string            `json:"performance"`
+	Result      json.RawMessage   `json:"result"`
+	Steps       []ExplanationStep `json:"steps"`
+}
+
+// ExplanationStep describes a single visited node.
+type ExplanationStep struct {
+	NodeID      string          `json:"nodeId"`
+	Name        string          `json:"name"`
+	Kind        NodeKind        `json:"kind"`
+	Performance string          `json:"performance,omitempty"`
+	Output      json.RawMessage `json:"output,omitempty"`
+	// MatchedRules are decision table rows which matched, more than one for collect hit policy.
+	MatchedRules []MatchedRule `json:"matchedRules,omitempty"`
+	// Statements are ids of switch statements whose branches were taken.
+	Statements []string `json:"statements,omitempty"`
+	// Expressions are results of expression node computations in the order of keys.
+	Expressions []ExpressionResult `json:"expressions,omitempty"`
+}
+
+// MatchedRule is a decision table row, Conditions hold satisfied input cells with the input values they were
+// evaluated against.
+type MatchedRule struct {
+	Index       int             `json:"index"`
+	ID          string          `json:"id,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Conditions  []RuleCondition `json:"conditions,omitempty"`
+}
+
+type RuleCondition struct {
+	// Column is the input column as reported by the engine, e.g. "Age[applicant.age]".
+	Column     string          `json:"column"`
+	Expression string          `json:"expression"`
+	Value      json.RawMessage `json:"value,omitempty"`
+}
+
+type ExpressionResult struct {
+	Key    string `json:"key"`
+	Result string `json:"result"`
+}
+
+// Explain turns the trace of response into an Explanation, response must be evaluated with Trace enabled.
+// Trace does not record order of execution, so steps are sorted by node id, use ExplainDecision to follow the graph.
+func Explain(response *EvaluationResponse) (*Explanation, error) {
+	if response == nil || response.Trace == nil {
+		return nil, errors.New("response has no trace, evaluate with Trace enabled")
+	}
+
+	trace := gjson.ParseBytes(*response.Trace)
+	if !trace.IsObject() {
+		return nil, errors.New("trace is not an object")
+	}
+
+	explanation := &Explanation{Performance: response.Performance, Result: response.Result}
+	trace.ForEach(func(key, node gjson.Result) bool {
+		explanation.Steps = append(explanation.Steps, explainNode(key.String(), node))
+		return true
+	})
+
+	sort.Slice(explanation.Steps, func(i, j int) bool {
+		return explanation.Steps[i].NodeID < explanation.Steps[j].NodeID
+	})
+
+	return explanation, nil
+}
+
+// ExplainDecision is Explain with steps ordered along the path taken through JDM content of the evaluated decision:
+// from the input node, every step follows the steps it depends on. Nodes not found in content come last.
+func ExplainDecision(content []byte, response *EvaluationResponse) (*Explanation, error) {
+	explanation, err := Explain(response)
+	if err != nil {
+		return nil, err
+	}
+
+	var jdm jdmContent
+	if err := json.Unmarshal(content, &jdm); err != nil {
+		return nil, err
+	}
+
+	position := make(map[string]int)
+	for i, nodeID := range graphOrder(jdm) {
+		position[nodeID] = i
+	}
+
+	sort.SliceStable(explanation.Steps, func(i, j int) bool {
+		pi, okI := position[explanation.Steps[i].NodeID]
+		pj, okJ := position[explanation.Steps[j].NodeID]
+		if okI != okJ {
+			return okI
+		}
+
+		return pi < pj
+	})
+
+	return explanation, nil
+}
+
+// graphOrder sorts nodes topologically, so every node follows the nodes it depends on. Nodes on a cycle are left out.
+func graphOrder(jdm jdmContent) []string {
+	index := make(map[string]int, len(jdm.Nodes))
+	for i, node := range jdm.Nodes {
+		index[node.ID] = i
+	}
+
+	incoming := make([]int, len(jdm.Nodes))
+	targets := make([][]int, len(jdm.Nodes))
+	for _, edge := range jdm.Edges {
+		source, okSource := index[edge.SourceID]
+		target, okTarget := index[edge.TargetID]
+		if okSource && okTarget {
+			targets[source] = append(targets[source], target)
+			incoming[target]++
+		}
+	}
+
+	// input nodes come first, then nodes in the order of content
+	before := func(a, b int) bool {
+		inputA, inputB := jdm.Nodes[a].Type == "inputNode", jdm.Nodes[b].Type == "inputNode"
+		if inputA != inputB {
+			return inputA
+		}
+
+		return a < b
+	}
+
+	var ready []int
+	for i := range jdm.Nodes {
+		if incoming[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	order := make([]string, 0, len(jdm.Nodes))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return before(ready[i], ready[j]) })
+		current := ready[0]
+		ready = ready[1:]
+		order = append(order, jdm.Nodes[current].ID)
+
+		for _, target := range targets[current] {
+			if incoming[target]--; incoming[target] == 0 {
+				ready = append(ready, target)
+			}
+		}
+	}
+
+	return order
+}
+
+func explainNode(nodeID string, node gjson.Result) ExplanationStep {
+	step := ExplanationStep{
+		NodeID:      nodeID,
+		Name:        node.Get("name").String(),
+		Kind:        NodeKindOther,
+		Performance: node.Get("performance").String(),
+	}
+
+	if id := node.Get("id").String(); id != "" {
+		step.NodeID = id
+	}
+
+	if output := node.Get("output"); output.Exists() && output.Type != gjson.Null {
+		step.Output = json.RawMessage(output.Raw)
+	}
+
+	traceData := node.Get("traceData")
+	switch {
+	case isTableTrace(traceData):
+		step.Kind = NodeKindDecisionTable
+		if traceData.IsArray() {
+			traceData.ForEach(func(_, row gjson.Result) bool {
+				step.MatchedRules = append(step.MatchedRules, explainRule(row))
+				return true
+			})
+		} else {
+			step.MatchedRules = []MatchedRule{explainRule(traceData)}
+		}
+	case traceData.Get("statements").IsArray():
+		step.Kind = NodeKindSwitch
+		traceData.Get("statements").ForEach(func(_, statement gjson.Result) bool {
+			step.Statements = append(step.Statements, statement.Get("id").String())
+			return true
+		})
+	case traceData.Get("log").Exists():
+		step.Kind = NodeKindFunction
+	case isExpressionTrace(traceData):
+		step.Kind = NodeKindExpression
+		traceData.ForEach(func(key, value gjson.Result) bool {
+			step.Expressions = append(step.Expressions, ExpressionResult{Key: key.String(), Result: value.Get("result").String()})
+			return true
+		})
+		sort.Slice(step.Expressions, func(i, j int) bool {
+			return step.Expressions[i].Key < step.Expressions[j].Key
+		})
+	}
+
+	return step
+}
+
+func isTableTrace(traceData gjson.Result) bool {
+	if traceData.IsArray() {
+		first := traceData.Get("0")
+		return first.Get("rule").IsObject() && first.Get("index").Exists()
+	}
+
+	return traceData.Get("rule").IsObject() && traceData.Get("index").Exists()
+}
+
+func isExpressionTrace(traceData gjson.Result) bool {
+	if !traceData.IsObject() {
+		return false
+	}
+
+	expression := true
+	count := 0
+	traceData.ForEach(func(_, value gjson.Result) bool {
+		count++
+		expression = value.IsObject() && value.Get("result").Exists()
+		return expression
+	})
+
+	return expression && count > 0
+}
+
+func explainRule(row gjson.Result) MatchedRule {
+	rule := MatchedRule{Index: int(row.Get("index").Int())}
+	referenceMap := row.Get("reference_map")
+
+	row.Get("rule").ForEach(func(key, value gjson.Result) bool {
+		switch column := key.String(); column {
+		case "_id":
+			rule.ID = value.String()
+		case "_description":
+			rule.Description = value.String()
+		default:
+			if value.String() == "" {
+				return true
+			}
+
+			condition := RuleCondition{Column: column, Expression: value.String()}
+			if field := columnField(column); field != "" {
+				if reference := referenceMap.Get(escapePathKey(field)); reference.Exists() {
+					condition.Value = json.RawMessage(reference.Raw)
+				}
+			}
+
+			rule.Conditions = append(rule.Conditions, condition)
+		}
+
+		return true
+	})
+
+	sort.Slice(rule.Conditions, func(i, j int) bool {
+		return rule.Conditions[i].Column < rule.Conditions[j].Column
+	})
+
+	return rule
+}
+
+// columnField extracts field from column names formatted as "Name[field]".
+func columnField(column string) string {
+	start := strings.LastIndexByte(column, '[')
+	if start < 0 || !strings.HasSuffix(column, "]") {
+		return ""
+	}
+
+	return column[start+1 : len(column)-1]
+}
+
+// String renders explanation as text, one line per step.
+func (e *Explanation) String() string {
+	var builder strings.Builder
+	for _, step := range e.Steps {
+		builder.WriteString(step.String())
+		builder.WriteByte('\n')
+	}
+
+	fmt.Fprintf(&builder, "Result: %s\n", e.Result)
+	return builder.String()
+}
+
+func (s ExplanationStep) String() string {
+	switch s.Kind {
+	case NodeKindDecisionTable:
+		if len(s.MatchedRules) == 0 {
+			return fmt.Sprintf("Decision table %q matched no rows", s.Name)
+		}
+
+		rows := make([]string, 0, len(s.MatchedRules))
+		for _, rule := range s.MatchedRules {
+			rows = append(rows, rule.String())
+		}
+
+		return fmt.Sprintf("Decision table %q matched %s, output %s", s.Name, strings.Join(rows, "; "), s.Output)
+	case NodeKindSwitch:
+		if len(s.Statements) == 0 {
+			return fmt.Sprintf("Switch %q took no branch", s.Name)
+		}
+
+		return fmt.Sprintf("Switch %q took branch %s", s.Name, strings.Join(s.Statements, ", "))
+	case NodeKindExpression:
+		results := make([]string, 0, len(s.Expressions))
+		for _, expression := range s.Expressions {
+			results = append(results, expression.Key+" = "+expression.Result)
+		}
+
+		return fmt.Sprintf("Expression %q computed %s", s.Name, strings.Join(results, ", "))
+	case NodeKindFunction:
+		return fmt.Sprintf("Function %q returned %s", s.Name, s.Output)
+	default:
+		if len(s.Output) == 0 {
+			return fmt.Sprintf("Node %q", s.Name)
+		}
+
+		return fmt.Sprintf("Node %q returned %s", s.Name, s.Output)
+	}
+}
+
+func (r MatchedRule) String() string {
+	row := fmt.Sprintf("row %d", r.Index+1)
+	if r.Description != "" {
+		row += fmt.Sprintf(" (%s)", r.Description)
+	}
+
+	if len(r.Conditions) == 0 {
+		return row + " with no conditions"
+	}
+
+	conditions := make([]string, 0, len(r.Conditions))
+	for _, condition := range r.Conditions {
+		text := condition.Column + " " + condition.Expression
+		if len(condition.Value) > 0 {
+			text += fmt.Sprintf(" (was %s)", condition.Value)
+		}
+
+		conditions = append(conditions, text)
+	}
+
+	return row + " where " + strings.Join(conditions, " and ")
+}
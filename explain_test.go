@@ -0,0 +1,156 @@
+package zen_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gorules/zen-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const explainTestTrace = `{
+	"3e3f5093": {"id": "3e3f5093", "name": "Request", "input": null, "output": {"age": 30, "amount": 1500}, "performance": "1µs", "traceData": null},
+	"a1b2c3d4": {"id": "a1b2c3d4", "name": "Route", "input": {}, "output": {}, "performance": "3µs", "traceData": {"statements": [{"id": "stmt-large"}]}},
+	"0624d5fd": {"id": "0624d5fd", "name": "Pricing", "input": {}, "output": {"rate": 0.1}, "performance": "10µs", "traceData": {
+		"index": 1,
+		"reference_map": {"age": 30, "amount": 1500},
+		"rule": {"_id": "row-2", "_description": "adults", "Age[age]": ">= 18", "Amount[amount]": "> 1000", "Country[country]": ""}
+	}},
+	"e5f6a7b8": {"id": "e5f6a7b8", "name": "Totals", "input": {}, "output": {"total": 150}, "performance": "2µs", "traceData": {"total": {"result": "150"}, "fee": {"result": "5"}}},
+	"f9e8d7c6": {"id": "f9e8d7c6", "name": "Adjust", "input": {}, "output": {"adjusted": true}, "performance": "1ms", "traceData": {"log": []}}
+}`
+
+func TestExplain(t *testing.T) {
+	trace := json.RawMessage(explainTestTrace)
+	explanation, err := zen.Explain(&zen.EvaluationResponse{
+		Performance: "1.1ms",
+		Result:      json.RawMessage(`{"rate":0.1}`),
+		Trace:       &trace,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, explanation.Steps, 5)
+
+	steps := make(map[string]zen.ExplanationStep)
+	for _, step := range explanation.Steps {
+		steps[step.Name] = step
+	}
+
+	assert.Equal(t, zen.NodeKindOther, steps["Request"].Kind)
+	assert.Equal(t, zen.NodeKindSwitch, steps["Route"].Kind)
+	assert.Equal(t, []string{"stmt-large"}, steps["Route"].Statements)
+	assert.Equal(t, zen.NodeKindFunction, steps["Adjust"].Kind)
+
+	assert.Equal(t, zen.NodeKindExpression, steps["Totals"].Kind)
+	assert.Equal(t, []zen.ExpressionResult{{Key: "fee", Result: "5"}, {Key: "total", Result: "150"}}, steps["Totals"].Expressions)
+
+	pricing := steps["Pricing"]
+	assert.Equal(t, zen.NodeKindDecisionTable, pricing.Kind)
+	assert.Equal(t, []zen.MatchedRule{{
+		Index:       1,
+		ID:          "row-2",
+		Description: "adults",
+		Conditions: []zen.RuleCondition{
+			{Column: "Age[age]", Expression: ">= 18", Value: json.RawMessage(`30`)},
+			{Column: "Amount[amount]", Expression: "> 1000", Value: json.RawMessage(`1500`)},
+		},
+	}}, pricing.MatchedRules)
+	assert.Equal(t, `Decision table "Pricing" matched row 2 (adults) where Age[age] >= 18 (was 30) and Amount[amount] > 1000 (was 1500), output {"rate": 0.1}`, pricing.String())
+
+	text := explanation.String()
+	assert.Contains(t, text, `Switch "Route" took branch stmt-large`)
+	assert.Contains(t, text, `Expression "Totals" computed fee = 5, total = 150`)
+	assert.Contains(t, text, `Result: {"rate":0.1}`)
+
+	data, err := json.Marshal(explanation)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"matchedRules":[{"index":1,"id":"row-2"`)
+}
+
+func TestExplain_WithoutTrace(t *testing.T) {
+	_, err := zen.Explain(&zen.EvaluationResponse{Result: json.RawMessage(`{}`)})
+	assert.Error(t, err)
+}
+
+func TestExplain_CollectTable(t *testing.T) {
+	trace := json.RawMessage(`{"t": {"id": "t", "name": "Fees", "output": [{"fee": 1}, {"fee": 2}], "traceData": [
+		{"index": 0, "reference_map": {}, "rule": {"_id": "a"}},
+		{"index": 3, "reference_map": {}, "rule": {"_id": "b"}}
+	]}}`)
+	explanation, err := zen.Explain(&zen.EvaluationResponse{Trace: &trace})
+	assert.NoError(t, err)
+	assert.Len(t, explanation.Steps[0].MatchedRules, 2)
+	assert.Equal(t, "b", explanation.Steps[0].MatchedRules[1].ID)
+	assert.Contains(t, explanation.Steps[0].String(), "row 1 with no conditions; row 4 with no conditions")
+}
+
+func TestExplainDecision(t *testing.T) {
+	content, err := readTestFile("table.json")
+	assert.NoError(t, err)
+
+	// trace keys are not in order of execution
+	trace := json.RawMessage(`{
+		"e0438c6b-dee0-405e-a941-9b4c3d9c4b83": {"id": "e0438c6b-dee0-405e-a941-9b4c3d9c4b83", "name": "Response", "traceData": null},
+		"3e3f5093-c969-4c3a-97e1-560e4b769a12": {"id": "3e3f5093-c969-4c3a-97e1-560e4b769a12", "name": "Request", "traceData": null},
+		"unknown": {"id": "unknown", "name": "Unknown", "traceData": null},
+		"0624d5fd-1944-4781-92bb-e32873ce91e2": {"id": "0624d5fd-1944-4781-92bb-e32873ce91e2", "name": "Hello", "traceData": {
+			"index": 0, "reference_map": {"input": 15}, "rule": {"_id": "5ZnYGPFT-N", "Input[input]": "> 10"}
+		}}
+	}`)
+
+	explanation, err := zen.ExplainDecision(content, &zen.EvaluationResponse{Trace: &trace})
+	assert.NoError(t, err)
+
+	var names []string
+	for _, step := range explanation.Steps {
+		names = append(names, step.Name)
+	}
+	assert.Equal(t, []string{"Request", "Hello", "Response", "Unknown"}, names)
+
+	// without content steps are sorted by node id
+	explanation, err = zen.Explain(&zen.EvaluationResponse{Trace: &trace})
+	assert.NoError(t, err)
+	assert.Equal(t, "0624d5fd-1944-4781-92bb-e32873ce91e2", explanation.Steps[0].NodeID)
+	assert.Equal(t, "unknown", explanation.Steps[3].NodeID)
+
+	_, err = zen.ExplainDecision([]byte(`not json`), &zen.EvaluationResponse{Trace: &trace})
+	assert.Error(t, err)
+}
+
+func TestExplain_Engine(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile})
+	defer engine.Dispose()
+
+	response, err := engine.EvaluateWithOpts("table.json", map[string]any{"input": 15}, zen.EvaluationOptions{Trace: true})
+	assert.NoError(t, err)
+
+	content, err := readTestFile("table.json")
+	assert.NoError(t, err)
+
+	explanation, err := zen.ExplainDecision(content, response)
+	assert.NoError(t, err)
+	if assert.Len(t, explanation.Steps, 3) {
+		assert.Equal(t, "Request", explanation.Steps[0].Name)
+		assert.Equal(t, "Hello", explanation.Steps[1].Name)
+		assert.Equal(t, "Response", explanation.Steps[2].Name)
+	}
+
+	steps := make(map[string]zen.ExplanationStep)
+	for _, step := range explanation.Steps {
+		steps[step.Name] = step
+	}
+
+	assert.Equal(t, zen.NodeKindOther, steps["Request"].Kind)
+	assert.Equal(t, zen.NodeKindOther, steps["Response"].Kind)
+
+	table := steps["Hello"]
+	assert.Equal(t, zen.NodeKindDecisionTable, table.Kind)
+	if assert.Len(t, table.MatchedRules, 1) {
+		rule := table.MatchedRules[0]
+		assert.Equal(t, 0, rule.Index)
+		assert.Equal(t, "5ZnYGPFT-N", rule.ID)
+		if assert.Len(t, rule.Conditions, 1) {
+			assert.Equal(t, "> 10", rule.Conditions[0].Expression)
+			assert.JSONEq(t, `15`, string(rule.Conditions[0].Value))
+		}
+	}
+}
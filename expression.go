@@ -4,43 +4,31 @@ package zen
 import "C"
 import (
 	"encoding/json"
-	"errors"
-	"fmt"
 	"unsafe"
 )
 
 func EvaluateExpression[T any](expression string, context any) (T, error) {
-	jsonData, err := extractJsonFromAny(context)
+	input, err := newJsonBuffer(JSONCodec{}, context)
 	if err != nil {
 		var zero T
 		return zero, err
 	}
+	defer input.release()
 
 	expressionCString := C.CString(expression)
 	defer C.free(unsafe.Pointer(expressionCString))
 
-	contextCString := C.CString(string(jsonData))
-	defer C.free(unsafe.Pointer(contextCString))
-
-	resultPtr := C.zen_evaluate_expression(expressionCString, contextCString)
+	resultPtr := C.zen_evaluate_expression(expressionCString, input.cString())
 	if resultPtr.error > 0 {
-		var errorDetails string
-		if resultPtr.details != nil {
-			defer C.free(unsafe.Pointer(resultPtr.details))
-			errorDetails = C.GoString(resultPtr.details)
-		} else {
-			errorDetails = fmt.Sprintf("Error code: %d", resultPtr.error)
-		}
-
 		var zero T
-		return zero, errors.New(errorDetails)
+		return zero, newEngineError(resultPtr.error, resultPtr.details)
 	}
 
 	defer C.free(unsafe.Pointer(resultPtr.result))
-	resultJson := C.GoString(resultPtr.result)
+	resultJson := goBytes(resultPtr.result)
 
 	var result T
-	if err := json.Unmarshal([]byte(resultJson), &result); err != nil {
+	if err := json.Unmarshal(resultJson, &result); err != nil {
 		var zero T
 		return zero, err
 	}
@@ -49,28 +37,18 @@ func EvaluateExpression[T any](expression string, context any) (T, error) {
 }
 
 func EvaluateUnaryExpression(expression string, context any) (bool, error) {
-	jsonData, err := extractJsonFromAny(context)
+	input, err := newJsonBuffer(JSONCodec{}, context)
 	if err != nil {
 		return false, err
 	}
+	defer input.release()
 
 	expressionCString := C.CString(expression)
 	defer C.free(unsafe.Pointer(expressionCString))
 
-	contextCString := C.CString(string(jsonData))
-	defer C.free(unsafe.Pointer(contextCString))
-
-	resultPtr := C.zen_evaluate_unary_expression(expressionCString, contextCString)
+	resultPtr := C.zen_evaluate_unary_expression(expressionCString, input.cString())
 	if resultPtr.error > 0 {
-		var errorDetails string
-		if resultPtr.details != nil {
-			defer C.free(unsafe.Pointer(resultPtr.details))
-			errorDetails = C.GoString(resultPtr.details)
-		} else {
-			errorDetails = fmt.Sprintf("Error code: %d", resultPtr.error)
-		}
-
-		return false, errors.New(errorDetails)
+		return false, newEngineError(resultPtr.error, resultPtr.details)
 	}
 
 	isSuccess := int(*resultPtr.result)
@@ -80,35 +58,25 @@ func EvaluateUnaryExpression(expression string, context any) (bool, error) {
 }
 
 func RenderTemplate[T any](template string, context any) (T, error) {
-	jsonData, err := extractJsonFromAny(context)
+	input, err := newJsonBuffer(JSONCodec{}, context)
 	if err != nil {
 		return *new(T), err
 	}
+	defer input.release()
 
 	templateCString := C.CString(template)
 	defer C.free(unsafe.Pointer(templateCString))
 
-	contextCString := C.CString(string(jsonData))
-	defer C.free(unsafe.Pointer(contextCString))
-
-	resultPtr := C.zen_evaluate_template(templateCString, contextCString)
+	resultPtr := C.zen_evaluate_template(templateCString, input.cString())
 	if resultPtr.error > 0 {
-		var errorDetails string
-		if resultPtr.details != nil {
-			defer C.free(unsafe.Pointer(resultPtr.details))
-			errorDetails = C.GoString(resultPtr.details)
-		} else {
-			errorDetails = fmt.Sprintf("Error code: %d", resultPtr.error)
-		}
-
-		return *new(T), errors.New(errorDetails)
+		return *new(T), newEngineError(resultPtr.error, resultPtr.details)
 	}
 
 	defer C.free(unsafe.Pointer(resultPtr.result))
-	resultJson := C.GoString(resultPtr.result)
+	resultJson := goBytes(resultPtr.result)
 
 	var result T
-	if err := json.Unmarshal([]byte(resultJson), &result); err != nil {
+	if err := json.Unmarshal(resultJson, &result); err != nil {
 		return *new(T), err
 	}
 
@@ -1,10 +1,11 @@
 module github.com/gorules/zen-go
 
-go 1.20
+go 1.21
 
 require (
 	github.com/stretchr/testify v1.8.4
 	github.com/tidwall/gjson v1.17.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -12,5 +13,4 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
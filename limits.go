@@ -0,0 +1,37 @@
+package zen
+
+// #include <string.h>
+// #include "zen_engine.h"
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+var (
+	// ErrInputTooLarge is returned when encoded input exceeds EvaluationOptions.MaxInputSize.
+	ErrInputTooLarge = errors.New("input exceeds maximum size")
+	// ErrOutputTooLarge is returned when encoded response exceeds EvaluationOptions.MaxOutputSize.
+	ErrOutputTooLarge = errors.New("output exceeds maximum size")
+	// ErrDepthLimitExceeded is returned when decision nodes recurse deeper than EvaluationOptions.MaxDepth.
+	ErrDepthLimitExceeded = errors.New("decision depth limit exceeded")
+)
+
+func checkInputSize(options EvaluationOptions, data []byte) error {
+	if options.MaxInputSize > 0 && len(data) > options.MaxInputSize {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrInputTooLarge, len(data), options.MaxInputSize)
+	}
+
+	return nil
+}
+
+// readResult measures the native result before it is copied into Go memory.
+func readResult(options EvaluationOptions, result *C.char) ([]byte, error) {
+	size := int(C.strlen(result))
+	if options.MaxOutputSize > 0 && size > options.MaxOutputSize {
+		return nil, fmt.Errorf("%w: %d bytes, limit is %d", ErrOutputTooLarge, size, options.MaxOutputSize)
+	}
+
+	return C.GoBytes(unsafe.Pointer(result), C.int(size)), nil
+}
@@ -0,0 +1,163 @@
+package zen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// RedactedValue replaces values of fields removed by RedactFields.
+const RedactedValue = "[REDACTED]"
+
+// logger emits debug events to EngineConfig.Logger, it does nothing when no logger is configured.
+type logger struct {
+	logger *slog.Logger
+	redact func(input []byte) []byte
+}
+
+func newLogger(config EngineConfig) logger {
+	return logger{logger: config.Logger, redact: config.RedactInput}
+}
+
+func (l logger) enabled() bool {
+	return l.logger != nil && l.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+func (l logger) loaded(key string, duration time.Duration, err error) {
+	if !l.enabled() {
+		return
+	}
+
+	attrs := []slog.Attr{slog.String("key", key), slog.Duration("duration", duration)}
+	if err != nil {
+		l.log("decision load failed", append(attrs, errorAttrs(err)...))
+		return
+	}
+
+	l.log("decision loaded", attrs)
+}
+
+func (l logger) compiled(key string, err error) {
+	if !l.enabled() {
+		return
+	}
+
+	attrs := []slog.Attr{slog.String("key", key)}
+	if err != nil {
+		l.log("decision compilation failed", append(attrs, errorAttrs(err)...))
+		return
+	}
+
+	l.log("decision compiled", attrs)
+}
+
+func (l logger) customNode(request NodeRequest, duration time.Duration, err error) {
+	if !l.enabled() {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("nodeId", request.Node.ID),
+		slog.String("nodeName", request.Node.Name),
+		slog.String("kind", request.Node.Kind),
+		slog.Duration("duration", duration),
+		l.input(request.Input),
+	}
+	if err != nil {
+		l.log("custom node failed", append(attrs, slog.String("error", err.Error())))
+		return
+	}
+
+	l.log("custom node called", attrs)
+}
+
+func (l logger) evaluationFailed(key string, input []byte, err error) {
+	if !l.enabled() {
+		return
+	}
+
+	attrs := []slog.Attr{slog.String("key", key), l.input(input)}
+	l.log("evaluation failed", append(attrs, errorAttrs(err)...))
+}
+
+func (l logger) log(msg string, attrs []slog.Attr) {
+	l.logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+}
+
+// input copies data, as evaluation inputs live in pooled buffers which are still used once logged.
+func (l logger) input(data []byte) slog.Attr {
+	if l.redact != nil {
+		data = l.redact(bytes.Clone(data))
+	}
+
+	return slog.String("input", string(data))
+}
+
+func errorAttrs(err error) []slog.Attr {
+	attrs := []slog.Attr{slog.String("error", err.Error())}
+
+	var nodeError *NodeError
+	if errors.As(err, &nodeError) {
+		attrs = append(attrs, slog.String("nodeId", nodeError.NodeID))
+	}
+
+	return attrs
+}
+
+// RedactFields returns a RedactInput hook which replaces values at dot separated paths with RedactedValue.
+// Arrays are traversed, so "applicants.ssn" redacts ssn of every applicant. Inputs which are not valid JSON are
+// replaced entirely.
+func RedactFields(paths ...string) func(input []byte) []byte {
+	split := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		split = append(split, strings.Split(path, "."))
+	}
+
+	return func(input []byte) []byte {
+		var data any
+		if err := (JSONCodec{UseNumber: true}).Unmarshal(input, &data); err != nil {
+			return []byte(`"` + RedactedValue + `"`)
+		}
+
+		for _, path := range split {
+			data = redactPath(data, path)
+		}
+
+		redacted, err := json.Marshal(data)
+		if err != nil {
+			return []byte(`"` + RedactedValue + `"`)
+		}
+
+		return redacted
+	}
+}
+
+func redactPath(data any, path []string) any {
+	switch value := data.(type) {
+	case map[string]any:
+		field, ok := value[path[0]]
+		if !ok {
+			return value
+		}
+
+		if len(path) == 1 {
+			value[path[0]] = RedactedValue
+		} else {
+			value[path[0]] = redactPath(field, path[1:])
+		}
+
+		return value
+	case []any:
+		for i, element := range value {
+			value[i] = redactPath(element, path)
+		}
+
+		return value
+	default:
+		return value
+	}
+}
@@ -0,0 +1,63 @@
+package otelzen
+
+import (
+	"context"
+
+	"github.com/gorules/zen-go"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WrapLoader emits a span around every call to loader. The native engine invokes loaders without a context,
+// so spans are root spans unless the loader is bound to an evaluation through WithContextPropagation.
+func WrapLoader(loader zen.Loader, opts ...Option) zen.Loader {
+	return wrapLoader(context.Background(), newConfig(opts), loader)
+}
+
+// WrapCustomNodeHandler emits a span around every call to handler, see WrapLoader for span parenting.
+func WrapCustomNodeHandler(handler zen.CustomNodeHandler, opts ...Option) zen.CustomNodeHandler {
+	return wrapCustomNodeHandler(context.Background(), newConfig(opts), handler)
+}
+
+func wrapLoader(ctx context.Context, c config, loader zen.Loader) zen.Loader {
+	if loader == nil {
+		return nil
+	}
+
+	return func(key string) ([]byte, error) {
+		_, span := c.tracer.Start(ctx, loadSpanName, trace.WithAttributes(c.decisionAttributes(key)...))
+		defer span.End()
+
+		content, err := loader(key)
+		recordError(span, err)
+		return content, err
+	}
+}
+
+func wrapCustomNodeHandler(ctx context.Context, c config, handler zen.CustomNodeHandler) zen.CustomNodeHandler {
+	if handler == nil {
+		return nil
+	}
+
+	return func(request zen.NodeRequest) (zen.NodeResponse, error) {
+		_, span := c.tracer.Start(ctx, customNodeSpanName, trace.WithAttributes(
+			NodeID.String(request.Node.ID),
+			NodeName.String(request.Node.Name),
+			NodeKind.String(request.Node.Kind),
+		))
+		defer span.End()
+
+		response, err := handler(request)
+		recordError(span, err)
+		return response, err
+	}
+}
+
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
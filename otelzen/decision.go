@@ -0,0 +1,44 @@
+package otelzen
+
+import (
+	"context"
+
+	"github.com/gorules/zen-go"
+)
+
+// Decision is an instrumented zen.Decision. Calls without a context are traced as if made with context.Background.
+type Decision struct {
+	zen.Decision
+
+	key      string
+	config   config
+	handlers zen.EngineConfig
+}
+
+// WrapDecision instruments decision, key is recorded on spans as the decision key.
+func WrapDecision(decision zen.Decision, key string, opts ...Option) *Decision {
+	return &Decision{Decision: decision, key: key, config: newConfig(opts)}
+}
+
+func (decision *Decision) Evaluate(context any) (*zen.EvaluationResponse, error) {
+	return decision.EvaluateWithOptsContext(contextBackground(), context, zen.EvaluationOptions{})
+}
+
+func (decision *Decision) EvaluateWithOpts(context any, options zen.EvaluationOptions) (*zen.EvaluationResponse, error) {
+	return decision.EvaluateWithOptsContext(contextBackground(), context, options)
+}
+
+func (decision *Decision) EvaluateContext(ctx context.Context, context any) (*zen.EvaluationResponse, error) {
+	return decision.EvaluateWithOptsContext(ctx, context, zen.EvaluationOptions{})
+}
+
+func (decision *Decision) EvaluateWithOptsContext(ctx context.Context, context any, options zen.EvaluationOptions) (*zen.EvaluationResponse, error) {
+	return decision.config.evaluate(ctx, decision.key, options, decision.handlers, func(options zen.EvaluationOptions) (*zen.EvaluationResponse, error) {
+		return decision.Decision.EvaluateWithOpts(context, options)
+	})
+}
+
+// EvaluateBatch passes the batch to the wrapped decision, items are not traced.
+func (decision *Decision) EvaluateBatch(contexts []any, options zen.EvaluationOptions) ([]zen.BatchResult, error) {
+	return zen.EvaluateBatch(decision.Decision, contexts, options)
+}
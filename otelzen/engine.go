@@ -0,0 +1,102 @@
+package otelzen
+
+import (
+	"context"
+
+	"github.com/gorules/zen-go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Engine is an instrumented zen.Engine. Calls without a context are traced as if made with context.Background.
+type Engine struct {
+	zen.Engine
+
+	config   config
+	handlers zen.EngineConfig
+}
+
+// NewEngine creates an engine from engineConfig, with loader and custom node handler wrapped in spans.
+func NewEngine(engineConfig zen.EngineConfig, opts ...Option) *Engine {
+	c := newConfig(opts)
+	handlers := zen.EngineConfig{Loader: engineConfig.Loader, CustomNodeHandler: engineConfig.CustomNodeHandler}
+
+	engineConfig.Loader = wrapLoader(context.Background(), c, engineConfig.Loader)
+	engineConfig.CustomNodeHandler = wrapCustomNodeHandler(context.Background(), c, engineConfig.CustomNodeHandler)
+
+	return &Engine{
+		Engine:   zen.NewEngine(engineConfig),
+		config:   c,
+		handlers: handlers,
+	}
+}
+
+func (engine *Engine) Evaluate(key string, context any) (*zen.EvaluationResponse, error) {
+	return engine.EvaluateWithOptsContext(contextBackground(), key, context, zen.EvaluationOptions{})
+}
+
+func (engine *Engine) EvaluateWithOpts(key string, context any, options zen.EvaluationOptions) (*zen.EvaluationResponse, error) {
+	return engine.EvaluateWithOptsContext(contextBackground(), key, context, options)
+}
+
+func (engine *Engine) EvaluateContext(ctx context.Context, key string, context any) (*zen.EvaluationResponse, error) {
+	return engine.EvaluateWithOptsContext(ctx, key, context, zen.EvaluationOptions{})
+}
+
+func (engine *Engine) EvaluateWithOptsContext(ctx context.Context, key string, context any, options zen.EvaluationOptions) (*zen.EvaluationResponse, error) {
+	return engine.config.evaluate(ctx, key, options, engine.handlers, func(options zen.EvaluationOptions) (*zen.EvaluationResponse, error) {
+		return engine.Engine.EvaluateWithOpts(key, context, options)
+	})
+}
+
+func (engine *Engine) GetDecision(key string) (zen.Decision, error) {
+	decision, err := engine.GetDecisionContext(contextBackground(), key)
+	if err != nil {
+		// a nil *Decision must not become a non-nil zen.Decision
+		return nil, err
+	}
+
+	return decision, nil
+}
+
+// GetDecisionContext loads and compiles decision within a span, the returned decision is instrumented.
+func (engine *Engine) GetDecisionContext(ctx context.Context, key string) (*Decision, error) {
+	_, span := engine.config.tracer.Start(ctx, getDecisionSpanName, trace.WithAttributes(engine.config.decisionAttributes(key)...))
+	defer span.End()
+
+	decision, err := engine.Engine.GetDecision(key)
+	if err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+
+	return &Decision{Decision: decision, key: key, config: engine.config, handlers: engine.handlers}, nil
+}
+
+// CreateDecision compiles data into an instrumented decision, spans carry an empty decision key.
+func (engine *Engine) CreateDecision(data []byte) (zen.Decision, error) {
+	decision, err := engine.Engine.CreateDecision(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Decision{Decision: decision, config: engine.config, handlers: engine.handlers}, nil
+}
+
+// Invalidate is passed to the wrapped engine when it implements zen.Invalidator.
+func (engine *Engine) Invalidate(key string) {
+	if invalidator, ok := engine.Engine.(zen.Invalidator); ok {
+		invalidator.Invalidate(key)
+	}
+}
+
+// InvalidateAll is passed to the wrapped engine when it implements zen.Invalidator.
+func (engine *Engine) InvalidateAll() {
+	if invalidator, ok := engine.Engine.(zen.Invalidator); ok {
+		invalidator.InvalidateAll()
+	}
+}
+
+// contextBackground is used where the evaluation context parameter shadows the context package.
+func contextBackground() context.Context {
+	return context.Background()
+}
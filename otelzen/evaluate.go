@@ -0,0 +1,111 @@
+package otelzen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/gorules/zen-go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type evaluateFunc func(options zen.EvaluationOptions) (*zen.EvaluationResponse, error)
+
+// evaluate runs a single evaluation within a span, handlers are the engine defaults bound to ctx on propagation.
+func (c config) evaluate(ctx context.Context, key string, options zen.EvaluationOptions, handlers zen.EngineConfig, evaluate evaluateFunc) (*zen.EvaluationResponse, error) {
+	start := time.Now()
+	ctx, span := c.tracer.Start(ctx, evaluateSpanName,
+		trace.WithTimestamp(start),
+		trace.WithAttributes(c.decisionAttributes(key)...),
+	)
+	defer span.End()
+
+	if c.contextPropagation {
+		options = c.bindCallbacks(ctx, options, handlers)
+	}
+
+	traceRequested := options.Trace
+	if c.nodeSpans {
+		options.Trace = true
+	}
+
+	response, err := evaluate(options)
+	if err != nil {
+		var nodeError *zen.NodeError
+		if errors.As(err, &nodeError) {
+			span.SetAttributes(NodeID.String(nodeError.NodeID))
+		}
+
+		recordError(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(EnginePerformance.String(response.Performance))
+	if c.nodeSpans && response.Trace != nil {
+		c.startNodeSpans(ctx, start, *response.Trace)
+	}
+
+	if !traceRequested {
+		response.Trace = nil
+	}
+
+	return response, nil
+}
+
+func (c config) bindCallbacks(ctx context.Context, options zen.EvaluationOptions, handlers zen.EngineConfig) zen.EvaluationOptions {
+	loader := options.Loader
+	if loader == nil {
+		loader = handlers.Loader
+	}
+
+	customNodeHandler := options.CustomNodeHandler
+	if customNodeHandler == nil {
+		customNodeHandler = handlers.CustomNodeHandler
+	}
+
+	options.Loader = wrapLoader(ctx, c, loader)
+	options.CustomNodeHandler = wrapCustomNodeHandler(ctx, c, customNodeHandler)
+	return options
+}
+
+type traceNode struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Performance string `json:"performance"`
+}
+
+// startNodeSpans reconstructs node spans from the trace. Trace does not carry start times, so every node span starts
+// with the evaluation and lasts for the performance reported by the engine.
+func (c config) startNodeSpans(ctx context.Context, start time.Time, data json.RawMessage) {
+	var nodes map[string]traceNode
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return
+	}
+
+	ids := make([]string, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		node := nodes[id]
+		if node.ID == "" {
+			node.ID = id
+		}
+
+		_, span := c.tracer.Start(ctx, nodeSpanNamePrefix+node.Name,
+			trace.WithTimestamp(start),
+			trace.WithAttributes(NodeID.String(node.ID), NodeName.String(node.Name)),
+		)
+
+		duration, err := time.ParseDuration(node.Performance)
+		if err != nil {
+			duration = 0
+		}
+
+		span.End(trace.WithTimestamp(start.Add(duration)))
+	}
+}
@@ -0,0 +1,87 @@
+// Package otelzen instruments zen engines and decisions with OpenTelemetry tracing.
+//
+// Evaluations started through EvaluateContext emit a span carrying the decision key, version, engine performance
+// and error. With WithNodeSpans, child spans are reconstructed for every node from the evaluation trace.
+// Loader and custom node callbacks are invoked by the native engine without a context, so their spans are
+// parented to the evaluation span only when WithContextPropagation is set.
+package otelzen
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/gorules/zen-go/otelzen"
+
+const (
+	DecisionKey         = attribute.Key("zen.decision.key")
+	DecisionVersion     = attribute.Key("zen.decision.version")
+	EnginePerformance   = attribute.Key("zen.performance")
+	NodeID              = attribute.Key("zen.node.id")
+	NodeName            = attribute.Key("zen.node.name")
+	NodeKind            = attribute.Key("zen.node.kind")
+	evaluateSpanName    = "zen.Evaluate"
+	getDecisionSpanName = "zen.GetDecision"
+	loadSpanName        = "zen.Load"
+	customNodeSpanName  = "zen.CustomNode"
+	nodeSpanNamePrefix  = "zen.node "
+)
+
+type config struct {
+	tracer             trace.Tracer
+	version            func(key string) string
+	nodeSpans          bool
+	contextPropagation bool
+}
+
+type Option func(*config)
+
+// WithTracerProvider sets the provider spans are created with, defaults to the global provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracer = provider.Tracer(instrumentationName)
+	}
+}
+
+// WithVersion resolves the decision version recorded on evaluation spans, e.g. a revision or content hash.
+func WithVersion(version func(key string) string) Option {
+	return func(c *config) {
+		c.version = version
+	}
+}
+
+// WithNodeSpans adds a child span per node reconstructed from the evaluation trace. Tracing is enabled for every
+// evaluation, and removed from the response again if the caller did not ask for it.
+func WithNodeSpans() Option {
+	return func(c *config) {
+		c.nodeSpans = true
+	}
+}
+
+// WithContextPropagation parents loader and custom node spans to the evaluation span. Callbacks bound to the
+// context are passed as per-evaluation overrides, so the decision is compiled in a scoped engine on every call
+// and the decision cache is bypassed.
+func WithContextPropagation() Option {
+	return func(c *config) {
+		c.contextPropagation = true
+	}
+}
+
+func newConfig(opts []Option) config {
+	c := config{tracer: otel.GetTracerProvider().Tracer(instrumentationName)}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+func (c config) decisionAttributes(key string) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{DecisionKey.String(key)}
+	if c.version != nil {
+		attributes = append(attributes, DecisionVersion.String(c.version(key)))
+	}
+
+	return attributes
+}
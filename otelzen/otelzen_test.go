@@ -0,0 +1,172 @@
+package otelzen_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gorules/zen-go"
+	"github.com/gorules/zen-go/otelzen"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func readTestFile(key string) ([]byte, error) {
+	filePath := path.Join("..", "test-data", key)
+	return os.ReadFile(filePath)
+}
+
+func customNodeHandler(request zen.NodeRequest) (zen.NodeResponse, error) {
+	return zen.NodeResponse{Output: map[string]any{"kind": request.Node.Kind}}, nil
+}
+
+func newRecorder() (*tracetest.SpanRecorder, otelzen.Option) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return recorder, otelzen.WithTracerProvider(provider)
+}
+
+func spansByName(recorder *tracetest.SpanRecorder) map[string][]sdktrace.ReadOnlySpan {
+	spans := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+
+	return spans
+}
+
+func attributeValue(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+
+	return ""
+}
+
+func TestEngine_EvaluateContext(t *testing.T) {
+	recorder, providerOption := newRecorder()
+	engine := otelzen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler},
+		providerOption,
+		otelzen.WithVersion(func(key string) string { return "v1" }),
+	)
+	defer engine.Dispose()
+
+	ctx, parent := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "parent")
+	response, err := engine.EvaluateContext(ctx, "table.json", map[string]any{"input": 15})
+	parent.End()
+	assert.NoError(t, err)
+	assert.Nil(t, response.Trace)
+
+	spans := spansByName(recorder)
+	assert.Len(t, spans["zen.Evaluate"], 1)
+	assert.Len(t, spans["zen.Load"], 1)
+
+	evaluateSpan := spans["zen.Evaluate"][0]
+	assert.Equal(t, parent.SpanContext().TraceID(), evaluateSpan.SpanContext().TraceID())
+	assert.Equal(t, "table.json", attributeValue(evaluateSpan, otelzen.DecisionKey))
+	assert.Equal(t, "v1", attributeValue(evaluateSpan, otelzen.DecisionVersion))
+	assert.Equal(t, response.Performance, attributeValue(evaluateSpan, otelzen.EnginePerformance))
+
+	// loader is called by the native engine without a context
+	assert.False(t, spans["zen.Load"][0].Parent().IsValid())
+}
+
+func TestEngine_EvaluateError(t *testing.T) {
+	recorder, providerOption := newRecorder()
+	engine := otelzen.NewEngine(zen.EngineConfig{Loader: func(key string) ([]byte, error) {
+		return nil, errors.New("not found")
+	}}, providerOption)
+	defer engine.Dispose()
+
+	_, err := engine.Evaluate("missing.json", map[string]any{})
+	assert.Error(t, err)
+
+	spans := spansByName(recorder)
+	assert.Len(t, spans["zen.Evaluate"], 1)
+	assert.Equal(t, "Error", spans["zen.Evaluate"][0].Status().Code.String())
+	assert.NotEmpty(t, spans["zen.Evaluate"][0].Events())
+}
+
+func TestEngine_ContextPropagation(t *testing.T) {
+	recorder, providerOption := newRecorder()
+	engine := otelzen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler},
+		providerOption,
+		otelzen.WithContextPropagation(),
+	)
+	defer engine.Dispose()
+
+	_, err := engine.EvaluateContext(context.Background(), "table.json", map[string]any{"input": 15})
+	assert.NoError(t, err)
+
+	spans := spansByName(recorder)
+	assert.Len(t, spans["zen.Load"], 1)
+	assert.Equal(t, spans["zen.Evaluate"][0].SpanContext().SpanID(), spans["zen.Load"][0].Parent().SpanID())
+}
+
+func TestDecision_NodeSpans(t *testing.T) {
+	recorder, providerOption := newRecorder()
+	engine := otelzen.NewEngine(zen.EngineConfig{Loader: readTestFile}, providerOption, otelzen.WithNodeSpans())
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecisionContext(context.Background(), "table.json")
+	assert.NoError(t, err)
+	defer decision.Dispose()
+
+	response, err := decision.EvaluateContext(context.Background(), map[string]any{"input": 15})
+	assert.NoError(t, err)
+	assert.Nil(t, response.Trace)
+
+	response, err = decision.EvaluateWithOptsContext(context.Background(), map[string]any{"input": 15}, zen.EvaluationOptions{Trace: true})
+	assert.NoError(t, err)
+	assert.NotNil(t, response.Trace)
+
+	spans := spansByName(recorder)
+	assert.Len(t, spans["zen.GetDecision"], 1)
+	assert.Len(t, spans["zen.Evaluate"], 2)
+
+	var nodeSpans int
+	for _, span := range recorder.Ended() {
+		if attributeValue(span, otelzen.NodeID) != "" && span.Name() != "zen.Evaluate" {
+			nodeSpans++
+			assert.True(t, span.Parent().IsValid())
+		}
+	}
+	assert.NotZero(t, nodeSpans)
+}
+
+func TestEngine_GetDecisionError(t *testing.T) {
+	_, providerOption := newRecorder()
+	engine := otelzen.NewEngine(zen.EngineConfig{Loader: func(key string) ([]byte, error) {
+		return nil, errors.New("not found")
+	}}, providerOption)
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("missing.json")
+	assert.Error(t, err)
+	assert.True(t, decision == nil, "interface must be nil, not a typed nil")
+}
+
+// plainEngine implements zen.Engine without zen.Invalidator.
+type plainEngine struct {
+	zen.Engine
+}
+
+func TestEngine_Invalidate(t *testing.T) {
+	engine := otelzen.NewEngine(zen.EngineConfig{Loader: readTestFile, DecisionCache: &zen.DecisionCacheConfig{}})
+	defer engine.Dispose()
+
+	engine.Invalidate("table.json")
+	engine.InvalidateAll()
+
+	wrapped := &otelzen.Engine{Engine: plainEngine{}}
+	assert.NotPanics(t, func() {
+		wrapped.Invalidate("table.json")
+		wrapped.InvalidateAll()
+	})
+}
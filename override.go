@@ -0,0 +1,157 @@
+package zen
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+func (options EvaluationOptions) hasOverrides() bool {
+	return options.Loader != nil || options.CustomNodeHandler != nil
+}
+
+func (options EvaluationOptions) withoutOverrides() EvaluationOptions {
+	options.Loader = nil
+	options.CustomNodeHandler = nil
+	return options
+}
+
+// scopedHandlers are consulted by loader and custom node callbacks of a scoped engine, so that a single native engine
+// serves per-call overrides of many calls, falling back to handlers of config where not overridden.
+type scopedHandlers struct {
+	config    EngineConfig
+	overrides atomic.Pointer[EvaluationOptions]
+}
+
+func (handlers *scopedHandlers) loader(key string) ([]byte, error) {
+	if overrides := handlers.overrides.Load(); overrides != nil && overrides.Loader != nil {
+		return overrides.Loader(key)
+	}
+
+	if handlers.config.Loader == nil {
+		return nil, errors.New("loader is not configured")
+	}
+
+	return handlers.config.Loader(key)
+}
+
+func (handlers *scopedHandlers) customNode(request NodeRequest) (NodeResponse, error) {
+	if overrides := handlers.overrides.Load(); overrides != nil && overrides.CustomNodeHandler != nil {
+		return overrides.CustomNodeHandler(request)
+	}
+
+	if handlers.config.CustomNodeHandler == nil {
+		return NodeResponse{}, errors.New("custom node handler is not configured")
+	}
+
+	return handlers.config.CustomNodeHandler(request)
+}
+
+// engineConfig creates configuration of the scoped engine, cache is left out as loaded content depends on the call.
+func (handlers *scopedHandlers) engineConfig() EngineConfig {
+	return EngineConfig{
+		Loader:            handlers.loader,
+		CustomNodeHandler: handlers.customNode,
+		Codec:             handlers.config.Codec,
+		Logger:            handlers.config.Logger,
+		RedactInput:       handlers.config.RedactInput,
+		Audit:             handlers.config.Audit,
+	}
+}
+
+type scopedEngine struct {
+	engine   *engine
+	handlers *scopedHandlers
+	// decision is compiled once per scoped engine when pool belongs to a decision
+	decision *decision
+}
+
+func (scoped *scopedEngine) dispose() {
+	if scoped.decision != nil {
+		scoped.decision.Dispose()
+	}
+
+	scoped.engine.Dispose()
+}
+
+// scopedPool keeps scoped engines for per-call overrides of an engine or a decision, instead of creating a native
+// engine per call. Every scoped engine serves a single call at a time, the pool grows to the number of concurrent
+// calls with overrides.
+type scopedPool struct {
+	config EngineConfig
+	// compile recreates decision owning the pool in a new scoped engine, it is nil for pools of engines
+	compile func(scoped *engine) (*decision, error)
+
+	mu     sync.Mutex
+	idle   []*scopedEngine
+	closed bool
+}
+
+func newScopedPool(config EngineConfig, compile func(scoped *engine) (*decision, error)) *scopedPool {
+	return &scopedPool{config: config, compile: compile}
+}
+
+// get returns a scoped engine whose handlers are overridden by options until it's put back.
+func (pool *scopedPool) get(options EvaluationOptions) (*scopedEngine, error) {
+	scoped, err := pool.idleOrNew()
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := EvaluationOptions{Loader: options.Loader, CustomNodeHandler: options.CustomNodeHandler}
+	scoped.handlers.overrides.Store(&overrides)
+	return scoped, nil
+}
+
+func (pool *scopedPool) idleOrNew() (*scopedEngine, error) {
+	pool.mu.Lock()
+	if n := len(pool.idle); n > 0 {
+		scoped := pool.idle[n-1]
+		pool.idle = pool.idle[:n-1]
+		pool.mu.Unlock()
+		return scoped, nil
+	}
+	pool.mu.Unlock()
+
+	handlers := &scopedHandlers{config: pool.config}
+	scoped := &scopedEngine{engine: NewEngine(handlers.engineConfig()).(*engine), handlers: handlers}
+	if pool.compile != nil {
+		decision, err := pool.compile(scoped.engine)
+		if err != nil {
+			scoped.engine.Dispose()
+			return nil, err
+		}
+
+		scoped.decision = decision
+	}
+
+	return scoped, nil
+}
+
+// put drops overrides of the finished call, scoped engines returned after close are freed.
+func (pool *scopedPool) put(scoped *scopedEngine) {
+	scoped.handlers.overrides.Store(nil)
+
+	pool.mu.Lock()
+	if !pool.closed {
+		pool.idle = append(pool.idle, scoped)
+		pool.mu.Unlock()
+		return
+	}
+	pool.mu.Unlock()
+
+	scoped.dispose()
+}
+
+// close frees idle scoped engines, those in use are freed once put back.
+func (pool *scopedPool) close() {
+	pool.mu.Lock()
+	pool.closed = true
+	idle := pool.idle
+	pool.idle = nil
+	pool.mu.Unlock()
+
+	for _, scoped := range idle {
+		scoped.dispose()
+	}
+}
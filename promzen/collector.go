@@ -0,0 +1,205 @@
+// Package promzen exposes Prometheus metrics for zen engines, decisions, loaders and custom nodes.
+//
+// Collector is registered like any other prometheus.Collector. Engine configuration is instrumented with
+// Collector.Instrument, evaluations with Collector.WrapEngine and Collector.WrapDecision.
+package promzen
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gorules/zen-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	OutcomeTimeout = "timeout"
+	OutcomeLimit   = "limit"
+
+	SourceEngine = "engine"
+	SourceWall   = "wall"
+)
+
+type config struct {
+	namespace string
+	buckets   []float64
+}
+
+type Option func(*config)
+
+// WithNamespace prefixes metric names, defaults to "zen".
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithBuckets sets histogram buckets in seconds, defaults to prometheus.DefBuckets.
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		c.buckets = buckets
+	}
+}
+
+type Collector struct {
+	evaluations        *prometheus.CounterVec
+	evaluationDuration *prometheus.HistogramVec
+	loads              *prometheus.CounterVec
+	loadDuration       prometheus.Histogram
+	cacheLookups       *prometheus.CounterVec
+	customNodes        *prometheus.CounterVec
+	customNodeDuration *prometheus.HistogramVec
+	liveDecisions      prometheus.GaugeFunc
+}
+
+func NewCollector(opts ...Option) *Collector {
+	c := config{namespace: "zen", buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return &Collector{
+		evaluations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: c.namespace,
+			Name:      "evaluations_total",
+			Help:      "Number of evaluations by decision key and outcome.",
+		}, []string{"key", "outcome"}),
+		evaluationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: c.namespace,
+			Name:      "evaluation_duration_seconds",
+			Help:      "Evaluation latency by decision key, as reported by the engine and as measured in Go.",
+			Buckets:   c.buckets,
+		}, []string{"key", "source"}),
+		loads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: c.namespace,
+			Name:      "loads_total",
+			Help:      "Number of loader calls by outcome.",
+		}, []string{"outcome"}),
+		loadDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: c.namespace,
+			Name:      "load_duration_seconds",
+			Help:      "Loader latency.",
+			Buckets:   c.buckets,
+		}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: c.namespace,
+			Name:      "decision_cache_lookups_total",
+			Help:      "Number of decision cache lookups by result.",
+		}, []string{"result"}),
+		customNodes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: c.namespace,
+			Name:      "custom_node_calls_total",
+			Help:      "Number of custom node calls by kind and outcome.",
+		}, []string{"kind", "outcome"}),
+		customNodeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: c.namespace,
+			Name:      "custom_node_duration_seconds",
+			Help:      "Custom node latency by kind.",
+			Buckets:   c.buckets,
+		}, []string{"kind"}),
+		liveDecisions: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: c.namespace,
+			Name:      "live_decisions",
+			Help:      "Number of decision handles not yet freed in the process.",
+		}, func() float64 {
+			return float64(zen.LiveDecisions())
+		}),
+	}
+}
+
+func (collector *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		collector.evaluations,
+		collector.evaluationDuration,
+		collector.loads,
+		collector.loadDuration,
+		collector.cacheLookups,
+		collector.customNodes,
+		collector.customNodeDuration,
+		collector.liveDecisions,
+	}
+}
+
+func (collector *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range collector.collectors() {
+		c.Describe(ch)
+	}
+}
+
+func (collector *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range collector.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// Instrument returns config with loader, custom node handler and decision cache lookups measured.
+func (collector *Collector) Instrument(config zen.EngineConfig) zen.EngineConfig {
+	if loader := config.Loader; loader != nil {
+		config.Loader = func(key string) ([]byte, error) {
+			start := time.Now()
+			content, err := loader(key)
+			collector.loadDuration.Observe(time.Since(start).Seconds())
+			collector.loads.WithLabelValues(outcome(err)).Inc()
+			return content, err
+		}
+	}
+
+	if handler := config.CustomNodeHandler; handler != nil {
+		config.CustomNodeHandler = func(request zen.NodeRequest) (zen.NodeResponse, error) {
+			start := time.Now()
+			response, err := handler(request)
+			collector.customNodeDuration.WithLabelValues(request.Node.Kind).Observe(time.Since(start).Seconds())
+			collector.customNodes.WithLabelValues(request.Node.Kind, outcome(err)).Inc()
+			return response, err
+		}
+	}
+
+	if config.DecisionCache != nil {
+		cache := *config.DecisionCache
+		onLookup := cache.OnLookup
+		cache.OnLookup = func(key string, hit bool) {
+			result := "miss"
+			if hit {
+				result = "hit"
+			}
+
+			collector.cacheLookups.WithLabelValues(result).Inc()
+			if onLookup != nil {
+				onLookup(key, hit)
+			}
+		}
+
+		config.DecisionCache = &cache
+	}
+
+	return config
+}
+
+func (collector *Collector) observeEvaluation(key string, start time.Time, response *zen.EvaluationResponse, err error) {
+	collector.evaluations.WithLabelValues(key, outcome(err)).Inc()
+	collector.evaluationDuration.WithLabelValues(key, SourceWall).Observe(time.Since(start).Seconds())
+	if err == nil {
+		collector.observePerformance(key, response)
+	}
+}
+
+func (collector *Collector) observePerformance(key string, response *zen.EvaluationResponse) {
+	if performance, err := time.ParseDuration(response.Performance); err == nil {
+		collector.evaluationDuration.WithLabelValues(key, SourceEngine).Observe(performance.Seconds())
+	}
+}
+
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, zen.ErrFunctionTimeout):
+		return OutcomeTimeout
+	case errors.Is(err, zen.ErrInputTooLarge), errors.Is(err, zen.ErrOutputTooLarge), errors.Is(err, zen.ErrDepthLimitExceeded):
+		return OutcomeLimit
+	default:
+		return OutcomeError
+	}
+}
@@ -0,0 +1,101 @@
+package promzen
+
+import (
+	"time"
+
+	"github.com/gorules/zen-go"
+)
+
+type engine struct {
+	zen.Engine
+	collector *Collector
+}
+
+// WrapEngine measures evaluations of engine, decisions it returns are measured as well.
+func (collector *Collector) WrapEngine(e zen.Engine) zen.Engine {
+	return &engine{Engine: e, collector: collector}
+}
+
+func (engine *engine) Evaluate(key string, context any) (*zen.EvaluationResponse, error) {
+	return engine.EvaluateWithOpts(key, context, zen.EvaluationOptions{})
+}
+
+func (engine *engine) EvaluateWithOpts(key string, context any, options zen.EvaluationOptions) (*zen.EvaluationResponse, error) {
+	start := time.Now()
+	response, err := engine.Engine.EvaluateWithOpts(key, context, options)
+	engine.collector.observeEvaluation(key, start, response, err)
+	return response, err
+}
+
+func (engine *engine) GetDecision(key string) (zen.Decision, error) {
+	d, err := engine.Engine.GetDecision(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.collector.WrapDecision(d, key), nil
+}
+
+// CreateDecision returns a decision measured under an empty key, use WrapDecision to name it.
+func (engine *engine) CreateDecision(data []byte) (zen.Decision, error) {
+	d, err := engine.Engine.CreateDecision(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.collector.WrapDecision(d, ""), nil
+}
+
+// Invalidate is passed to the wrapped engine when it implements zen.Invalidator.
+func (engine *engine) Invalidate(key string) {
+	if invalidator, ok := engine.Engine.(zen.Invalidator); ok {
+		invalidator.Invalidate(key)
+	}
+}
+
+// InvalidateAll is passed to the wrapped engine when it implements zen.Invalidator.
+func (engine *engine) InvalidateAll() {
+	if invalidator, ok := engine.Engine.(zen.Invalidator); ok {
+		invalidator.InvalidateAll()
+	}
+}
+
+type decision struct {
+	zen.Decision
+	key       string
+	collector *Collector
+}
+
+// WrapDecision measures evaluations of d, key is used as the decision key label.
+func (collector *Collector) WrapDecision(d zen.Decision, key string) zen.Decision {
+	return &decision{Decision: d, key: key, collector: collector}
+}
+
+func (decision *decision) Evaluate(context any) (*zen.EvaluationResponse, error) {
+	return decision.EvaluateWithOpts(context, zen.EvaluationOptions{})
+}
+
+func (decision *decision) EvaluateWithOpts(context any, options zen.EvaluationOptions) (*zen.EvaluationResponse, error) {
+	start := time.Now()
+	response, err := decision.Decision.EvaluateWithOpts(context, options)
+	decision.collector.observeEvaluation(decision.key, start, response, err)
+	return response, err
+}
+
+// EvaluateBatch counts every item of the batch, wall time is not observed as items are evaluated concurrently.
+func (decision *decision) EvaluateBatch(contexts []any, options zen.EvaluationOptions) ([]zen.BatchResult, error) {
+	results, err := zen.EvaluateBatch(decision.Decision, contexts, options)
+	if err != nil {
+		decision.collector.evaluations.WithLabelValues(decision.key, outcome(err)).Inc()
+		return nil, err
+	}
+
+	for _, result := range results {
+		decision.collector.evaluations.WithLabelValues(decision.key, outcome(result.Err)).Inc()
+		if result.Err == nil {
+			decision.collector.observePerformance(decision.key, result.Response)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,114 @@
+package promzen_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/gorules/zen-go"
+	"github.com/gorules/zen-go/promzen"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func readTestFile(key string) ([]byte, error) {
+	filePath := path.Join("..", "test-data", key)
+	return os.ReadFile(filePath)
+}
+
+func customNodeHandler(request zen.NodeRequest) (zen.NodeResponse, error) {
+	return zen.NodeResponse{Output: map[string]any{"kind": request.Node.Kind}}, nil
+}
+
+func TestCollector_Register(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	assert.NoError(t, registry.Register(promzen.NewCollector(promzen.WithNamespace("rules"))))
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	var names []string
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	assert.Contains(t, names, "rules_live_decisions")
+}
+
+func TestCollector_Evaluations(t *testing.T) {
+	collector := promzen.NewCollector()
+	engine := collector.WrapEngine(zen.NewEngine(collector.Instrument(zen.EngineConfig{
+		Loader:            readTestFile,
+		CustomNodeHandler: customNodeHandler,
+		DecisionCache:     &zen.DecisionCacheConfig{},
+	})))
+	defer engine.Dispose()
+
+	for i := 0; i < 3; i++ {
+		_, err := engine.Evaluate("table.json", map[string]any{"input": 5})
+		assert.NoError(t, err)
+	}
+
+	_, err := engine.Evaluate("missing.json", map[string]any{})
+	assert.Error(t, err)
+
+	_, err = engine.EvaluateWithOpts("table.json", map[string]any{"input": 5}, zen.EvaluationOptions{MaxInputSize: 1})
+	assert.ErrorIs(t, err, zen.ErrInputTooLarge)
+
+	expected := `
+# HELP zen_evaluations_total Number of evaluations by decision key and outcome.
+# TYPE zen_evaluations_total counter
+zen_evaluations_total{key="missing.json",outcome="error"} 1
+zen_evaluations_total{key="table.json",outcome="limit"} 1
+zen_evaluations_total{key="table.json",outcome="success"} 3
+# HELP zen_decision_cache_lookups_total Number of decision cache lookups by result.
+# TYPE zen_decision_cache_lookups_total counter
+zen_decision_cache_lookups_total{result="hit"} 3
+zen_decision_cache_lookups_total{result="miss"} 2
+# HELP zen_loads_total Number of loader calls by outcome.
+# TYPE zen_loads_total counter
+zen_loads_total{outcome="error"} 1
+zen_loads_total{outcome="success"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"zen_evaluations_total", "zen_decision_cache_lookups_total", "zen_loads_total"))
+	assert.Equal(t, 3, testutil.CollectAndCount(collector, "zen_evaluation_duration_seconds"))
+}
+
+func TestCollector_Decision(t *testing.T) {
+	collector := promzen.NewCollector()
+	engine := collector.WrapEngine(zen.NewEngine(collector.Instrument(zen.EngineConfig{Loader: readTestFile})))
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("table.json")
+	assert.NoError(t, err)
+
+	live := fmt.Sprintf(`
+# HELP zen_live_decisions Number of decision handles not yet freed in the process.
+# TYPE zen_live_decisions gauge
+zen_live_decisions %d
+`, zen.LiveDecisions())
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(live), "zen_live_decisions"))
+
+	_, err = decision.Evaluate(map[string]any{"input": 5})
+	assert.NoError(t, err)
+
+	results, err := zen.EvaluateBatch(decision, []any{map[string]any{"input": 1}, map[string]any{"input": 2}}, zen.EvaluationOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	decision.Dispose()
+	_, err = decision.Evaluate(map[string]any{"input": 5})
+	assert.True(t, errors.Is(err, zen.ErrDisposed))
+
+	expected := `
+# HELP zen_evaluations_total Number of evaluations by decision key and outcome.
+# TYPE zen_evaluations_total counter
+zen_evaluations_total{key="table.json",outcome="error"} 1
+zen_evaluations_total{key="table.json",outcome="success"} 3
+`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "zen_evaluations_total"))
+}
@@ -0,0 +1,7 @@
+//go:build !zen_debug
+// +build !zen_debug
+
+package zen
+
+// warnLeaked is a no-op outside of zen_debug builds.
+func warnLeaked(string) {}
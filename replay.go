@@ -0,0 +1,72 @@
+package zen
+
+import "errors"
+
+// ReplayResult compares a replayed evaluation with its AuditRecord.
+type ReplayResult struct {
+	Response *EvaluationResponse
+	Error    string
+	// Diffs lists fields of the replayed output which differ from the recorded output.
+	Diffs []FieldDiff
+	// ErrorChanged reports that evaluation failed with a different error, or failed or succeeded only once.
+	ErrorChanged bool
+	// ContentChanged reports that decision content does not match the recorded hash.
+	ContentChanged bool
+}
+
+// Matches reports whether replay reproduced the recorded outcome.
+func (r *ReplayResult) Matches() bool {
+	return !r.ErrorChanged && len(r.Diffs) == 0
+}
+
+// Replay re-evaluates record with a new engine created from config and diffs the result. Decision content is taken
+// from the record when it was stored, otherwise it is loaded using config.Loader. Returned error is reserved for
+// failures to load or compile the decision, evaluation errors are reported in ReplayResult.
+func Replay(record AuditRecord, config EngineConfig) (*ReplayResult, error) {
+	content := []byte(record.Content)
+	if len(content) == 0 {
+		if config.Loader == nil {
+			return nil, errors.New("audit record has no content and no loader is configured")
+		}
+
+		var err error
+		if content, err = config.Loader(record.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	config.Audit = nil
+	config.DecisionCache = nil
+	engine := NewEngine(config)
+	defer engine.Dispose()
+
+	decision, err := engine.CreateDecision(content)
+	if err != nil {
+		return nil, err
+	}
+	defer decision.Dispose()
+
+	result := &ReplayResult{}
+	if record.ContentHash != "" {
+		result.ContentChanged = contentHash(content) != record.ContentHash
+	}
+
+	response, err := decision.EvaluateWithOpts(record.Input, record.Options)
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorChanged = result.Error != record.Error
+		return result, nil
+	}
+
+	result.Response = response
+	if record.Error != "" {
+		result.ErrorChanged = true
+		return result, nil
+	}
+
+	if result.Diffs, err = DiffJSON(record.Output, response.Result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package zen
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrDisposed is returned when an engine or a decision is used after Dispose.
+var ErrDisposed = errors.New("use of disposed engine or decision")
+
+// resource counts references to a native handle. Owner holds the initial reference which is dropped by dispose,
+// in-flight calls and dependent handles (decisions of an engine) hold their own, so handle is freed only after it was
+// disposed and nothing uses it anymore.
+type resource struct {
+	mu       sync.Mutex
+	refs     int
+	disposed bool
+	free     func()
+}
+
+func newResource(free func()) *resource {
+	return &resource{refs: 1, free: free}
+}
+
+func (r *resource) acquire() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.disposed {
+		return ErrDisposed
+	}
+
+	r.refs++
+	return nil
+}
+
+// retain adds a reference, caller must already hold one (e.g. from acquire) for the duration of retain call.
+func (r *resource) retain() {
+	r.mu.Lock()
+	r.refs++
+	r.mu.Unlock()
+}
+
+func (r *resource) release() {
+	r.mu.Lock()
+	r.refs--
+	done := r.refs == 0
+	r.mu.Unlock()
+
+	if done {
+		r.free()
+	}
+}
+
+// dispose drops owner reference, it returns false if resource has already been disposed.
+func (r *resource) dispose() bool {
+	r.mu.Lock()
+	if r.disposed {
+		r.mu.Unlock()
+		return false
+	}
+
+	r.disposed = true
+	r.mu.Unlock()
+
+	r.release()
+	return true
+}
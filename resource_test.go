@@ -0,0 +1,24 @@
+package zen
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestResource(t *testing.T) {
+	freed := 0
+	r := newResource(func() { freed++ })
+
+	assert.NoError(t, r.acquire())
+	assert.True(t, r.dispose())
+	assert.False(t, r.dispose())
+	assert.Equal(t, 0, freed, "in-flight call keeps resource alive")
+
+	assert.ErrorIs(t, r.acquire(), ErrDisposed)
+
+	r.release()
+	assert.Equal(t, 1, freed)
+
+	assert.False(t, r.dispose())
+	assert.Equal(t, 1, freed)
+}
@@ -0,0 +1,395 @@
+package zen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ShadowConfig configures evaluation of a candidate alongside the primary engine or decision.
+type ShadowConfig struct {
+	// SampleRate is the share of primary evaluations repeated by the candidate, from 0 to 1. Input of a sampled call is
+	// encoded once more on the calling goroutine, as the caller may modify it once the call returns.
+	SampleRate float64
+	// MaxConcurrency bounds candidate evaluations in flight, samples beyond it are dropped. Defaults to GOMAXPROCS.
+	MaxConcurrency int
+	// OnMismatch is called from a background goroutine for every sample whose candidate outcome differs.
+	OnMismatch func(mismatch ShadowMismatch)
+	// Codec encodes sampled inputs for the candidate. Defaults to the Codec of primary created by NewEngine, otherwise
+	// to JSONCodec.
+	Codec Codec
+}
+
+// ShadowMismatch describes a sampled evaluation for which the candidate disagreed with the primary.
+// Expected of Diffs refers to the primary result.
+type ShadowMismatch struct {
+	Key            string
+	Input          json.RawMessage
+	Diffs          []FieldDiff
+	PrimaryError   string
+	CandidateError string
+}
+
+func (s ShadowMismatch) String() string {
+	if s.PrimaryError != "" || s.CandidateError != "" {
+		return fmt.Sprintf("%s: primary error %q, candidate error %q", s.Key, s.PrimaryError, s.CandidateError)
+	}
+
+	return fmt.Sprintf("%s: %d fields differ", s.Key, len(s.Diffs))
+}
+
+// ShadowStats are counters of sampled evaluations. Failed counts samples which could not be compared, for example
+// because the input could not be encoded or the candidate panicked.
+type ShadowStats struct {
+	Sampled    uint64
+	Dropped    uint64
+	Matched    uint64
+	Mismatched uint64
+	Failed     uint64
+}
+
+// shadow runs candidate evaluations in the background, it never blocks or fails the primary call.
+// Decisions of a ShadowEngine share its semaphore and counters, but track their own evaluations in flight.
+type shadow struct {
+	config    ShadowConfig
+	codec     Codec
+	semaphore chan struct{}
+	counters  *shadowCounters
+
+	// mu guards inFlight and closed, idle is signalled once inFlight drops to zero
+	mu       sync.Mutex
+	idle     *sync.Cond
+	inFlight int
+	closed   bool
+}
+
+type shadowCounters struct {
+	sampled    atomic.Uint64
+	dropped    atomic.Uint64
+	matched    atomic.Uint64
+	mismatched atomic.Uint64
+	failed     atomic.Uint64
+}
+
+// newShadow falls back to codec of the primary engine or decision, when config doesn't set one.
+func newShadow(config ShadowConfig, primaryCodec Codec) *shadow {
+	concurrency := config.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	codec := config.Codec
+	if codec == nil {
+		codec = codecOrDefault(primaryCodec)
+	}
+
+	s := &shadow{config: config, codec: codec, semaphore: make(chan struct{}, concurrency), counters: &shadowCounters{}}
+	s.idle = sync.NewCond(&s.mu)
+	return s
+}
+
+// child shares configuration, semaphore and counters of s.
+func (s *shadow) child() *shadow {
+	c := &shadow{config: s.config, codec: s.codec, semaphore: s.semaphore, counters: s.counters}
+	c.idle = sync.NewCond(&c.mu)
+	return c
+}
+
+// primaryCodec returns Codec of engines and decisions created by this package.
+func primaryCodec(primary any) Codec {
+	switch p := primary.(type) {
+	case *engine:
+		return p.codec
+	case *decision:
+		return p.codec
+	case *EnginePool:
+		return p.shards[0].engine.codec
+	default:
+		return nil
+	}
+}
+
+// dispatch samples a finished primary evaluation and evaluates the candidate with a copy of its input.
+func (s *shadow) dispatch(key string, context any, response *EvaluationResponse, err error, candidate func(input json.RawMessage) (*EvaluationResponse, error)) {
+	if s.config.SampleRate <= 0 || rand.Float64() >= s.config.SampleRate {
+		return
+	}
+
+	if !s.begin() {
+		return
+	}
+
+	s.counters.sampled.Add(1)
+	select {
+	case s.semaphore <- struct{}{}:
+	default:
+		s.counters.dropped.Add(1)
+		s.done()
+		return
+	}
+
+	// caller owns input and response once the primary call returns
+	input, encodeErr := s.encode(context)
+	if encodeErr != nil {
+		<-s.semaphore
+		s.counters.failed.Add(1)
+		s.done()
+		return
+	}
+
+	primary := shadowOutcome{err: err}
+	if err == nil {
+		primary.result = bytes.Clone(response.Result)
+	}
+
+	go func() {
+		defer s.done()
+		defer func() { <-s.semaphore }()
+		defer func() {
+			if recover() != nil {
+				s.counters.failed.Add(1)
+			}
+		}()
+
+		candidateResponse, candidateErr := candidate(input)
+		s.compare(key, input, primary, candidateResponse, candidateErr)
+	}()
+}
+
+// encode copies raw inputs and encodes others with the codec, so the candidate sees the same input as the primary.
+func (s *shadow) encode(context any) (json.RawMessage, error) {
+	switch data := context.(type) {
+	case json.RawMessage:
+		return bytes.Clone(data), nil
+	case []byte:
+		return bytes.Clone(data), nil
+	default:
+		return s.codec.Marshal(context)
+	}
+}
+
+// begin counts a dispatch in flight, it reports false once the shadow is closed.
+func (s *shadow) begin() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.inFlight++
+	return true
+}
+
+func (s *shadow) done() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight--
+	if s.inFlight == 0 {
+		s.idle.Broadcast()
+	}
+}
+
+// wait blocks until no candidate evaluation is in flight, close additionally stops new ones from being dispatched.
+func (s *shadow) wait(close bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if close {
+		s.closed = true
+	}
+
+	for s.inFlight > 0 {
+		s.idle.Wait()
+	}
+}
+
+type shadowOutcome struct {
+	result json.RawMessage
+	err    error
+}
+
+func (s *shadow) compare(key string, input json.RawMessage, primary shadowOutcome, response *EvaluationResponse, err error) {
+	mismatch := ShadowMismatch{Key: key, Input: input}
+	if primary.err != nil {
+		mismatch.PrimaryError = primary.err.Error()
+	}
+
+	if err != nil {
+		mismatch.CandidateError = err.Error()
+	}
+
+	if primary.err == nil && err == nil {
+		diffs, diffErr := DiffJSON(primary.result, response.Result)
+		if diffErr != nil {
+			s.counters.failed.Add(1)
+			return
+		}
+
+		mismatch.Diffs = diffs
+	}
+
+	if len(mismatch.Diffs) == 0 && mismatch.PrimaryError == mismatch.CandidateError {
+		s.counters.matched.Add(1)
+		return
+	}
+
+	s.counters.mismatched.Add(1)
+	if s.config.OnMismatch != nil {
+		s.config.OnMismatch(mismatch)
+	}
+}
+
+func (s *shadow) stats() ShadowStats {
+	return ShadowStats{
+		Sampled:    s.counters.sampled.Load(),
+		Dropped:    s.counters.dropped.Load(),
+		Matched:    s.counters.matched.Load(),
+		Mismatched: s.counters.mismatched.Load(),
+		Failed:     s.counters.failed.Load(),
+	}
+}
+
+// ShadowEngine returns results of the primary engine, and evaluates a sample of calls with the candidate in the
+// background to report mismatches. It implements Engine.
+type ShadowEngine struct {
+	primary   Engine
+	candidate Engine
+	shadow    *shadow
+}
+
+func NewShadowEngine(primary, candidate Engine, config ShadowConfig) *ShadowEngine {
+	return &ShadowEngine{primary: primary, candidate: candidate, shadow: newShadow(config, primaryCodec(primary))}
+}
+
+func (engine *ShadowEngine) Evaluate(key string, context any) (*EvaluationResponse, error) {
+	return engine.EvaluateWithOpts(key, context, EvaluationOptions{})
+}
+
+func (engine *ShadowEngine) EvaluateWithOpts(key string, context any, options EvaluationOptions) (*EvaluationResponse, error) {
+	response, err := engine.primary.EvaluateWithOpts(key, context, options)
+	engine.shadow.dispatch(key, context, response, err, func(input json.RawMessage) (*EvaluationResponse, error) {
+		return engine.candidate.EvaluateWithOpts(key, input, options)
+	})
+
+	return response, err
+}
+
+// GetDecision returns a shadowed decision, or the primary decision alone when the candidate fails to load it.
+func (engine *ShadowEngine) GetDecision(key string) (Decision, error) {
+	primary, err := engine.primary.GetDecision(key)
+	if err != nil {
+		return nil, err
+	}
+
+	candidate, err := engine.candidate.GetDecision(key)
+	if err != nil {
+		engine.shadow.counters.failed.Add(1)
+		return primary, nil
+	}
+
+	return &ShadowDecision{primary: primary, candidate: candidate, key: key, shadow: engine.shadow.child()}, nil
+}
+
+// CreateDecision creates a primary decision only, as there is no candidate content to compare with.
+func (engine *ShadowEngine) CreateDecision(data []byte) (Decision, error) {
+	return engine.primary.CreateDecision(data)
+}
+
+// Invalidate removes decision from caches of both engines which implement Invalidator.
+func (engine *ShadowEngine) Invalidate(key string) {
+	for _, e := range []Engine{engine.primary, engine.candidate} {
+		if invalidator, ok := e.(Invalidator); ok {
+			invalidator.Invalidate(key)
+		}
+	}
+}
+
+// InvalidateAll removes all decisions from caches of both engines which implement Invalidator.
+func (engine *ShadowEngine) InvalidateAll() {
+	for _, e := range []Engine{engine.primary, engine.candidate} {
+		if invalidator, ok := e.(Invalidator); ok {
+			invalidator.InvalidateAll()
+		}
+	}
+}
+
+func (engine *ShadowEngine) Stats() ShadowStats {
+	return engine.shadow.stats()
+}
+
+// Wait blocks until candidate evaluations in flight are finished, evaluations of its decisions are not awaited.
+func (engine *ShadowEngine) Wait() {
+	engine.shadow.wait(false)
+}
+
+// Dispose stops sampling, waits for candidate evaluations in flight and disposes both engines.
+func (engine *ShadowEngine) Dispose() {
+	engine.shadow.wait(true)
+	engine.primary.Dispose()
+	engine.candidate.Dispose()
+}
+
+// ShadowDecision is the Decision counterpart of ShadowEngine.
+type ShadowDecision struct {
+	primary   Decision
+	candidate Decision
+	key       string
+	shadow    *shadow
+}
+
+// NewShadowDecision shadows primary with candidate, key is only used to label mismatches.
+func NewShadowDecision(primary, candidate Decision, key string, config ShadowConfig) *ShadowDecision {
+	return &ShadowDecision{primary: primary, candidate: candidate, key: key, shadow: newShadow(config, primaryCodec(primary))}
+}
+
+func (decision *ShadowDecision) Evaluate(context any) (*EvaluationResponse, error) {
+	return decision.EvaluateWithOpts(context, EvaluationOptions{})
+}
+
+func (decision *ShadowDecision) EvaluateWithOpts(context any, options EvaluationOptions) (*EvaluationResponse, error) {
+	response, err := decision.primary.EvaluateWithOpts(context, options)
+	decision.shadow.dispatch(decision.key, context, response, err, func(input json.RawMessage) (*EvaluationResponse, error) {
+		return decision.candidate.EvaluateWithOpts(input, options)
+	})
+
+	return response, err
+}
+
+// EvaluateBatch samples every item of the batch separately.
+func (decision *ShadowDecision) EvaluateBatch(contexts []any, options EvaluationOptions) ([]BatchResult, error) {
+	results, err := EvaluateBatch(decision.primary, contexts, options)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		decision.shadow.dispatch(decision.key, contexts[i], result.Response, result.Err, func(input json.RawMessage) (*EvaluationResponse, error) {
+			return decision.candidate.EvaluateWithOpts(input, options)
+		})
+	}
+
+	return results, nil
+}
+
+// Stats of decisions returned by ShadowEngine.GetDecision are shared with the engine.
+func (decision *ShadowDecision) Stats() ShadowStats {
+	return decision.shadow.stats()
+}
+
+// Wait blocks until candidate evaluations in flight are finished.
+func (decision *ShadowDecision) Wait() {
+	decision.shadow.wait(false)
+}
+
+// Dispose stops sampling, waits for candidate evaluations in flight and disposes both decisions.
+func (decision *ShadowDecision) Dispose() {
+	decision.shadow.wait(true)
+	decision.primary.Dispose()
+	decision.candidate.Dispose()
+}
@@ -0,0 +1,261 @@
+package zen_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorules/zen-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// decisionEngine serves a single decision for every key.
+type decisionEngine struct {
+	decision zen.Decision
+}
+
+func (engine decisionEngine) Evaluate(key string, context any) (*zen.EvaluationResponse, error) {
+	return engine.EvaluateWithOpts(key, context, zen.EvaluationOptions{})
+}
+
+func (engine decisionEngine) EvaluateWithOpts(_ string, context any, options zen.EvaluationOptions) (*zen.EvaluationResponse, error) {
+	return engine.decision.EvaluateWithOpts(context, options)
+}
+
+func (engine decisionEngine) GetDecision(string) (zen.Decision, error) {
+	return engine.decision, nil
+}
+
+func (engine decisionEngine) CreateDecision([]byte) (zen.Decision, error) {
+	return engine.decision, nil
+}
+
+func (engine decisionEngine) Dispose() {}
+
+func constDecision(result string) funcDecision {
+	return funcDecision{evaluate: func(map[string]any) (string, error) {
+		return result, nil
+	}}
+}
+
+type mismatchRecorder struct {
+	mu         sync.Mutex
+	mismatches []zen.ShadowMismatch
+}
+
+func (r *mismatchRecorder) record(mismatch zen.ShadowMismatch) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mismatches = append(r.mismatches, mismatch)
+}
+
+func TestShadowDecision(t *testing.T) {
+	candidate := funcDecision{evaluate: func(input map[string]any) (string, error) {
+		switch fmt.Sprint(input["age"]) {
+		case "70":
+			return `{"tier": "senior"}`, nil
+		case "-1":
+			return "", errors.New("invalid age")
+		default:
+			return `{"tier": "standard"}`, nil
+		}
+	}}
+
+	var recorder mismatchRecorder
+	shadow := zen.NewShadowDecision(constDecision(`{"tier": "standard"}`), candidate, "pricing", zen.ShadowConfig{
+		SampleRate:     1,
+		MaxConcurrency: 8,
+		OnMismatch:     recorder.record,
+	})
+
+	for _, age := range []int{30, 70, -1} {
+		response, err := shadow.Evaluate(map[string]any{"age": age})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"tier": "standard"}`, string(response.Result))
+	}
+
+	shadow.Wait()
+	assert.Equal(t, zen.ShadowStats{Sampled: 3, Matched: 1, Mismatched: 2}, shadow.Stats())
+	assert.Len(t, recorder.mismatches, 2)
+
+	for _, mismatch := range recorder.mismatches {
+		assert.Equal(t, "pricing", mismatch.Key)
+		switch string(mismatch.Input) {
+		case `{"age":70}`:
+			assert.Len(t, mismatch.Diffs, 1)
+			assert.Equal(t, "tier", mismatch.Diffs[0].Path)
+		case `{"age":-1}`:
+			assert.Empty(t, mismatch.PrimaryError)
+			assert.Equal(t, "invalid age", mismatch.CandidateError)
+		default:
+			t.Errorf("unexpected mismatch for %s", mismatch.Input)
+		}
+	}
+}
+
+func TestShadowDecision_Sampling(t *testing.T) {
+	var calls atomic.Int32
+	candidate := funcDecision{evaluate: func(map[string]any) (string, error) {
+		calls.Add(1)
+		return `{}`, nil
+	}}
+
+	never := zen.NewShadowDecision(constDecision(`{}`), candidate, "", zen.ShadowConfig{SampleRate: 0})
+	for i := 0; i < 10; i++ {
+		_, err := never.Evaluate(map[string]any{"i": i})
+		assert.NoError(t, err)
+	}
+
+	never.Wait()
+	assert.Equal(t, zen.ShadowStats{}, never.Stats())
+	assert.Equal(t, int32(0), calls.Load())
+
+	always := zen.NewShadowDecision(constDecision(`{}`), candidate, "", zen.ShadowConfig{SampleRate: 1, MaxConcurrency: 16})
+	for i := 0; i < 10; i++ {
+		_, err := always.Evaluate(map[string]any{"i": i})
+		assert.NoError(t, err)
+	}
+
+	always.Wait()
+	assert.Equal(t, zen.ShadowStats{Sampled: 10, Matched: 10}, always.Stats())
+	assert.Equal(t, int32(10), calls.Load())
+}
+
+func TestShadowDecision_Dropped(t *testing.T) {
+	release := make(chan struct{})
+	candidate := funcDecision{evaluate: func(map[string]any) (string, error) {
+		<-release
+		return `{}`, nil
+	}}
+
+	shadow := zen.NewShadowDecision(constDecision(`{}`), candidate, "", zen.ShadowConfig{SampleRate: 1, MaxConcurrency: 1})
+	for i := 0; i < 3; i++ {
+		// primary calls return while the candidate is blocked
+		_, err := shadow.Evaluate(map[string]any{"i": i})
+		assert.NoError(t, err)
+	}
+
+	close(release)
+	shadow.Wait()
+	assert.Equal(t, zen.ShadowStats{Sampled: 3, Dropped: 2, Matched: 1}, shadow.Stats())
+}
+
+func TestShadowDecision_CandidatePanic(t *testing.T) {
+	candidate := funcDecision{evaluate: func(map[string]any) (string, error) { panic("candidate failed") }}
+
+	shadow := zen.NewShadowDecision(constDecision(`{"ok": true}`), candidate, "", zen.ShadowConfig{SampleRate: 1, MaxConcurrency: 16})
+	response, err := shadow.Evaluate(map[string]any{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"ok": true}`, string(response.Result))
+
+	shadow.Wait()
+	assert.Equal(t, zen.ShadowStats{Sampled: 1, Failed: 1}, shadow.Stats())
+}
+
+// upperCodec encodes keys in upper case, so candidates reveal which codec encoded their input.
+type upperCodec struct {
+	zen.JSONCodec
+}
+
+func (upperCodec) Marshal(v any) ([]byte, error) {
+	upper := make(map[string]any)
+	for key, value := range v.(map[string]any) {
+		upper[fmt.Sprintf("%s_UPPER", key)] = value
+	}
+
+	return json.Marshal(upper)
+}
+
+func TestShadowDecision_Codec(t *testing.T) {
+	var recorder mismatchRecorder
+	candidate := funcDecision{evaluate: func(input map[string]any) (string, error) {
+		return fmt.Sprintf(`{"age": %v}`, input["age_UPPER"]), nil
+	}}
+
+	shadow := zen.NewShadowDecision(constDecision(`{"age": 30}`), candidate, "", zen.ShadowConfig{
+		SampleRate:     1,
+		MaxConcurrency: 16,
+		Codec:          upperCodec{},
+		OnMismatch:     recorder.record,
+	})
+
+	_, err := shadow.Evaluate(map[string]any{"age": 30})
+	assert.NoError(t, err)
+
+	// raw inputs are passed as they are
+	_, err = shadow.Evaluate(json.RawMessage(`{"age_UPPER": 30}`))
+	assert.NoError(t, err)
+
+	shadow.Wait()
+	assert.Equal(t, zen.ShadowStats{Sampled: 2, Matched: 2}, shadow.Stats())
+	assert.Empty(t, recorder.mismatches)
+}
+
+func TestShadowDecision_Dispose(t *testing.T) {
+	var calls atomic.Int32
+	candidate := funcDecision{evaluate: func(map[string]any) (string, error) {
+		calls.Add(1)
+		return `{}`, nil
+	}}
+
+	shadow := zen.NewShadowDecision(constDecision(`{}`), candidate, "", zen.ShadowConfig{SampleRate: 1, MaxConcurrency: 4})
+
+	// Dispose racing with evaluations waits for dispatched candidates and stops new ones
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = shadow.Evaluate(map[string]any{"j": j})
+			}
+		}()
+	}
+
+	shadow.Dispose()
+	evaluated := calls.Load()
+	wg.Wait()
+
+	stats := shadow.Stats()
+	assert.Equal(t, evaluated, calls.Load(), "no candidate runs after Dispose")
+	assert.Equal(t, stats.Sampled, stats.Dropped+stats.Matched)
+}
+
+func TestShadowEngine(t *testing.T) {
+	primary := decisionEngine{decision: funcDecision{evaluate: func(map[string]any) (string, error) {
+		return "", errors.New("primary failed")
+	}}}
+	candidate := decisionEngine{decision: constDecision(`{"ok": true}`)}
+
+	var recorder mismatchRecorder
+	shadow := zen.NewShadowEngine(primary, candidate, zen.ShadowConfig{
+		SampleRate:     1,
+		MaxConcurrency: 16,
+		OnMismatch:     recorder.record,
+	})
+
+	_, err := shadow.Evaluate("pricing", json.RawMessage(`{"age":30}`))
+	assert.EqualError(t, err, "primary failed")
+
+	decision, err := shadow.GetDecision("pricing")
+	assert.NoError(t, err)
+	_, err = decision.Evaluate(map[string]any{"age": 40})
+	assert.EqualError(t, err, "primary failed")
+
+	// disposing a decision does not stop sampling of the engine
+	decision.Dispose()
+	_, err = shadow.Evaluate("pricing", json.RawMessage(`{"age":50}`))
+	assert.EqualError(t, err, "primary failed")
+
+	shadow.Dispose()
+	assert.Equal(t, zen.ShadowStats{Sampled: 3, Mismatched: 3}, shadow.Stats())
+	assert.Len(t, recorder.mismatches, 3)
+	for _, mismatch := range recorder.mismatches {
+		assert.Equal(t, "pricing", mismatch.Key)
+		assert.Equal(t, "primary failed", mismatch.PrimaryError)
+		assert.Empty(t, mismatch.CandidateError)
+	}
+}
@@ -0,0 +1,138 @@
+package zen
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"runtime"
+)
+
+// StreamOptions configures EvaluateStream, embedded EvaluationOptions are applied to every record.
+type StreamOptions struct {
+	EvaluationOptions
+	// Concurrency bounds the number of records evaluated at once, defaults to GOMAXPROCS.
+	Concurrency int
+}
+
+// StreamRecord is written as a single line of output for every non-empty line of input.
+type StreamRecord struct {
+	Line        int              `json:"line"`
+	Performance string           `json:"performance,omitempty"`
+	Result      json.RawMessage  `json:"result,omitempty"`
+	Trace       *json.RawMessage `json:"trace,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// EvaluateStream reads NDJSON records from r, evaluates each of them using decision and writes StreamRecord lines to w
+// in the order of input. Records are evaluated concurrently, but only a bounded number of them is held in memory.
+// Evaluation errors are reported per line, returned error is reserved for reading, writing and context failures.
+func EvaluateStream(ctx context.Context, decision Decision, r io.Reader, w io.Writer, options StreamOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	semaphore := make(chan struct{}, concurrency)
+	pending := make(chan chan StreamRecord, concurrency)
+	readErr := make(chan error, 1)
+
+	go func() {
+		defer close(pending)
+
+		reader := bufio.NewReader(r)
+		for line := 1; ; line++ {
+			data, err := reader.ReadBytes('\n')
+			if data = bytes.TrimSpace(data); len(data) > 0 {
+				select {
+				case semaphore <- struct{}{}:
+				case <-ctx.Done():
+					readErr <- ctx.Err()
+					return
+				}
+
+				// select picks randomly among ready cases, so cancellation is checked before every record
+				if err := ctx.Err(); err != nil {
+					readErr <- err
+					return
+				}
+
+				result := make(chan StreamRecord, 1)
+				select {
+				case pending <- result:
+				case <-ctx.Done():
+					readErr <- ctx.Err()
+					return
+				}
+
+				go func(line int, data []byte) {
+					defer func() { <-semaphore }()
+
+					if err := ctx.Err(); err != nil {
+						result <- StreamRecord{Line: line, Error: err.Error()}
+						return
+					}
+
+					response, err := decision.EvaluateWithOpts(data, options.EvaluationOptions)
+					result <- newStreamRecord(line, response, err)
+				}(line, data)
+			}
+
+			if err == io.EOF {
+				readErr <- nil
+				return
+			}
+
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+
+	for result := range pending {
+		select {
+		case record := <-result:
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if err := <-readErr; err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+func newStreamRecord(line int, response *EvaluationResponse, err error) StreamRecord {
+	if err != nil {
+		return StreamRecord{Line: line, Error: err.Error()}
+	}
+
+	return StreamRecord{
+		Line:        line,
+		Performance: response.Performance,
+		Result:      response.Result,
+		Trace:       response.Trace,
+	}
+}
@@ -0,0 +1,106 @@
+package zen_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorules/zen-go"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestEvaluateStream(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("function.json")
+	assert.NoError(t, err)
+	defer decision.Dispose()
+
+	var input strings.Builder
+	for i := 0; i < 100; i++ {
+		if i == 50 {
+			input.WriteString("{\"input\":\n")
+			continue
+		}
+
+		input.WriteString(fmt.Sprintf("{\"input\":%d}\n", i))
+	}
+
+	var output bytes.Buffer
+	err = zen.EvaluateStream(context.Background(), decision, strings.NewReader(input.String()), &output, zen.StreamOptions{
+		EvaluationOptions: zen.EvaluationOptions{Trace: true},
+		Concurrency:       4,
+	})
+	assert.NoError(t, err)
+
+	type responseData struct {
+		Output int `json:"output"`
+	}
+
+	var records []zen.StreamRecord
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		var record zen.StreamRecord
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+
+	assert.Len(t, records, 100)
+	for i, record := range records {
+		assert.Equal(t, i+1, record.Line)
+		if i == 50 {
+			assert.NotEmpty(t, record.Error)
+			continue
+		}
+
+		assert.Empty(t, record.Error)
+		assert.NotNil(t, record.Trace)
+
+		var respData responseData
+		assert.NoError(t, json.Unmarshal(record.Result, &respData))
+		assert.Equal(t, i*2, respData.Output)
+	}
+}
+
+func TestEvaluateStream_Cancelled(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: readTestFile, CustomNodeHandler: customNodeHandler})
+	defer engine.Dispose()
+
+	decision, err := engine.GetDecision("function.json")
+	assert.NoError(t, err)
+	defer decision.Dispose()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i := 0; i < 100; i++ {
+		var output bytes.Buffer
+		err = zen.EvaluateStream(ctx, decision, strings.NewReader("{\"input\":1}\n"), &output, zen.StreamOptions{})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, output.String())
+	}
+}
+
+func TestEvaluateStream_CancelledDuringEvaluation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var evaluated atomic.Int32
+	decision := funcDecision{evaluate: func(input map[string]any) (string, error) {
+		if evaluated.Add(1) == 2 {
+			cancel()
+		}
+
+		return `{}`, nil
+	}}
+
+	var output bytes.Buffer
+	err := zen.EvaluateStream(ctx, decision, strings.NewReader("{\"input\":1}\n{\"input\":2}\n{\"input\":3}\n"), &output, zen.StreamOptions{Concurrency: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(2), evaluated.Load(), "records after cancellation are not evaluated")
+}
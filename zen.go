@@ -5,12 +5,27 @@ import "encoding/json"
 type EvaluationOptions struct {
 	Trace    bool  `json:"trace"`
 	MaxDepth uint8 `json:"maxDepth"`
+	// MaxInputSize limits encoded input in bytes, checked before input is passed to the engine. Zero means no limit.
+	MaxInputSize int `json:"maxInputSize"`
+	// MaxOutputSize limits encoded response in bytes, checked before response is copied from the engine. Zero means no limit.
+	MaxOutputSize int `json:"maxOutputSize"`
+	// Loader overrides the engine loader for this call only.
+	Loader Loader `json:"-"`
+	// CustomNodeHandler overrides the engine custom node handler for this call only.
+	CustomNodeHandler CustomNodeHandler `json:"-"`
 }
 
 type EvaluationResponse struct {
 	Performance string           `json:"performance"`
 	Result      json.RawMessage  `json:"result"`
 	Trace       *json.RawMessage `json:"trace"`
+
+	codec Codec
+}
+
+// DecodeResult decodes Result into v using the Codec of the engine which produced the response.
+func (r *EvaluationResponse) DecodeResult(v any) error {
+	return codecOrDefault(r.codec).Unmarshal(r.Result, v)
 }
 
 type Engine interface {
@@ -21,8 +36,19 @@ type Engine interface {
 	Dispose()
 }
 
+// Invalidator is implemented by engines which cache decisions, see EngineConfig.DecisionCache.
+type Invalidator interface {
+	Invalidate(key string)
+	InvalidateAll()
+}
+
 type Decision interface {
 	Evaluate(context any) (*EvaluationResponse, error)
 	EvaluateWithOpts(context any, options EvaluationOptions) (*EvaluationResponse, error)
 	Dispose()
 }
+
+// BatchEvaluator is implemented by decisions which evaluate many contexts at once, see EvaluateBatch.
+type BatchEvaluator interface {
+	EvaluateBatch(contexts []any, options EvaluationOptions) ([]BatchResult, error)
+}
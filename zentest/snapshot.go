@@ -0,0 +1,220 @@
+package zentest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gorules/zen-go"
+)
+
+type SnapshotOptions struct {
+	// Dir holds golden files, one directory per decision, defaults to testdata/golden.
+	Dir string
+	// Trace stores normalized trace next to output, see NormalizeTrace.
+	Trace bool
+	// Update writes golden files instead of comparing with them, usually wired to a -update flag of the test package.
+	Update bool
+}
+
+// Snapshot is the content of a golden file.
+type Snapshot struct {
+	Output json.RawMessage `json:"output,omitempty"`
+	Trace  json.RawMessage `json:"trace,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// ReadCorpus reads every JSON file in dir as an input, keyed by file name without extension.
+func ReadCorpus(dir string) (map[string]any, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	corpus := make(map[string]any)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("%s: invalid JSON", entry.Name())
+		}
+
+		corpus[strings.TrimSuffix(entry.Name(), ".json")] = json.RawMessage(data)
+	}
+
+	return corpus, nil
+}
+
+// MatchSnapshots evaluates every input of corpus using decision key and compares results with golden files, each
+// input as a subtest of t. Golden files are written instead when options.Update is set.
+func MatchSnapshots(t *testing.T, engine zen.Engine, key string, corpus map[string]any, options SnapshotOptions) {
+	t.Helper()
+
+	dir := options.Dir
+	if dir == "" {
+		dir = filepath.Join("testdata", "golden")
+	}
+	dir = filepath.Join(dir, filepath.FromSlash(strings.TrimSuffix(key, ".json")))
+
+	names := make([]string, 0, len(corpus))
+	for name := range corpus {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			actual, err := takeSnapshot(engine, key, corpus[name], options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join(dir, name+".golden.json")
+			if options.Update {
+				if err := writeGolden(path, actual); err != nil {
+					t.Fatal(err)
+				}
+
+				return
+			}
+
+			expected, err := os.ReadFile(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("golden file %s does not exist, run with SnapshotOptions.Update to create it", path)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, failure := range compareSnapshot(expected, actual) {
+				t.Error(failure)
+			}
+		})
+	}
+}
+
+func takeSnapshot(engine zen.Engine, key string, input any, options SnapshotOptions) ([]byte, error) {
+	var snapshot Snapshot
+	response, err := engine.EvaluateWithOpts(key, input, zen.EvaluationOptions{Trace: options.Trace})
+	if err != nil {
+		snapshot.Error = err.Error()
+	} else {
+		snapshot.Output = response.Result
+		if options.Trace && response.Trace != nil {
+			if snapshot.Trace, err = NormalizeTrace(*response.Trace); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return marshalIndent(snapshot)
+}
+
+// marshalIndent sorts object keys, so golden files do not change with the order produced by the engine.
+func marshalIndent(snapshot Snapshot) ([]byte, error) {
+	var value any
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := (zen.JSONCodec{UseNumber: true}).Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func writeGolden(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func compareSnapshot(expected, actual []byte) []string {
+	diffs, err := zen.DiffJSON(expected, actual)
+	if err != nil {
+		return []string{fmt.Sprintf("golden file: %v", err)}
+	}
+
+	failures := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		failures = append(failures, fmt.Sprintf("%s: expected %s, got %s", diff.Path, orMissing(diff.Expected), orMissing(diff.Actual)))
+	}
+
+	return failures
+}
+
+// NormalizeTrace removes timings from trace, so it only changes when evaluation does. Only performance of trace
+// nodes is removed, inputs and outputs of nodes are kept as they are.
+func NormalizeTrace(trace json.RawMessage) (json.RawMessage, error) {
+	var value map[string]any
+	if err := (zen.JSONCodec{UseNumber: true}).Unmarshal(trace, &value); err != nil {
+		return nil, err
+	}
+
+	removeTimings(value)
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return normalized, nil
+}
+
+// removeTimings drops performance of trace nodes, including nodes of nested decisions whose trace is reported as
+// trace data of the decision node.
+func removeTimings(trace map[string]any) {
+	for _, value := range trace {
+		node, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		delete(node, "performance")
+		if nested, ok := node["traceData"].(map[string]any); ok && isTrace(nested) {
+			removeTimings(nested)
+		}
+	}
+}
+
+// isTrace reports whether every entry of value is a trace node, keyed by its id.
+func isTrace(value map[string]any) bool {
+	if len(value) == 0 {
+		return false
+	}
+
+	for key, entry := range value {
+		node, ok := entry.(map[string]any)
+		if !ok || node["id"] != key {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,17 @@
+package zentest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSnapshot(t *testing.T) {
+	expected := []byte(`{"output": {"rate": 0.1, "tier": "standard"}}`)
+	assert.Empty(t, compareSnapshot(expected, []byte(`{"output": {"tier": "standard", "rate": 0.10}}`)))
+	assert.Equal(t, []string{
+		`error: expected <missing>, got "age is required"`,
+		`output: expected {"rate":0.1,"tier":"standard"}, got <missing>`,
+	}, compareSnapshot(expected, []byte(`{"error": "age is required"}`)))
+	assert.Equal(t, []string{`output.rate: expected 0.1, got 0.2`}, compareSnapshot(expected, []byte(`{"output": {"rate": 0.2, "tier": "standard"}}`)))
+}
@@ -0,0 +1,70 @@
+package zentest_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorules/zen-go/zentest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCorpus(t *testing.T) {
+	corpus, err := zentest.ReadCorpus(filepath.Join("testdata", "corpus"))
+	assert.NoError(t, err)
+	assert.Len(t, corpus, 2)
+	assert.JSONEq(t, `{"age": 30}`, string(corpus["adult"].(json.RawMessage)))
+}
+
+func TestMatchSnapshots(t *testing.T) {
+	corpus, err := zentest.ReadCorpus(filepath.Join("testdata", "corpus"))
+	assert.NoError(t, err)
+
+	engine := fakeEngine{results: map[string]string{"pricing.json": `{"tier": "standard", "rate": 0.1}`}}
+	zentest.MatchSnapshots(t, engine, "pricing.json", corpus, zentest.SnapshotOptions{Trace: true})
+}
+
+func TestMatchSnapshots_Update(t *testing.T) {
+	dir := t.TempDir()
+	engine := fakeEngine{results: map[string]string{"rules/pricing.json": `{"tier": "standard", "rate": 0.1}`}}
+	zentest.MatchSnapshots(t, engine, "rules/pricing.json", map[string]any{"adult": map[string]any{"age": 30}}, zentest.SnapshotOptions{Dir: dir, Update: true})
+
+	golden, err := os.ReadFile(filepath.Join(dir, "rules", "pricing", "adult.golden.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "output": {
+    "rate": 0.1,
+    "tier": "standard"
+  }
+}
+`, string(golden))
+}
+
+func TestNormalizeTrace(t *testing.T) {
+	normalized, err := zentest.NormalizeTrace(json.RawMessage(`{
+		"n1": {"id": "n1", "name": "Decision", "performance": "1ms", "output": {"performance": 1}, "traceData": {
+			"n2": {"id": "n2", "name": "Nested", "performance": "2µs"}
+		}}
+	}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"n1": {"id": "n1", "name": "Decision", "output": {"performance": 1}, "traceData": {
+		"n2": {"id": "n2", "name": "Nested"}
+	}}}`, string(normalized))
+}
+
+func TestNormalizeTrace_KeepsNodeData(t *testing.T) {
+	normalized, err := zentest.NormalizeTrace(json.RawMessage(`{
+		"n1": {"id": "n1", "name": "Pricing", "performance": "1ms",
+			"input": {"id": "item-1", "name": "Widget", "performance": 0.8},
+			"output": {"id": "item-1", "name": "Widget", "performance": 0.9},
+			"traceData": {"total": {"result": "150"}}
+		}
+	}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"n1": {"id": "n1", "name": "Pricing",
+		"input": {"id": "item-1", "name": "Widget", "performance": 0.8},
+		"output": {"id": "item-1", "name": "Widget", "performance": 0.9},
+		"traceData": {"total": {"result": "150"}}
+	}}`, string(normalized))
+}
@@ -0,0 +1,290 @@
+// Package zentest runs declarative test cases for JDM decisions.
+//
+// Test files live next to decisions and are named after them, e.g. pricing.test.json, pricing.test.yaml or
+// pricing.test.yml for pricing.json:
+//
+//	tolerance: 0.001
+//	cases:
+//	  - name: adult
+//	    input: {age: 30}
+//	    output: {rate: 0.1}
+//	  - name: minor
+//	    input: {age: 12}
+//	    assertions:
+//	      - path: rate
+//	        equals: 0
+//	      - path: reason
+//	        exists: true
+//	  - name: invalid
+//	    input: {}
+//	    error: age
+//
+// Output is compared as a whole, assertions compare single fields addressed by gjson paths. Numbers are equal when
+// they differ by at most the tolerance of the assertion, case or suite, in this order.
+package zentest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorules/zen-go"
+	"github.com/tidwall/gjson"
+	"gopkg.in/yaml.v3"
+)
+
+var testFileSuffixes = []string{".test.json", ".test.yaml", ".test.yml"}
+
+// Suite holds test cases of a single decision.
+type Suite struct {
+	// Key is the loader key of the decision, path of the decision file relative to the discovered directory.
+	Key       string  `json:"-" yaml:"-"`
+	Path      string  `json:"-" yaml:"-"`
+	Tolerance float64 `json:"tolerance" yaml:"tolerance"`
+	Cases     []Case  `json:"cases" yaml:"cases"`
+}
+
+type Case struct {
+	Name  string `json:"name" yaml:"name"`
+	Input any    `json:"input" yaml:"input"`
+	// Output is compared with the whole result when set.
+	Output     any         `json:"output" yaml:"output"`
+	Assertions []Assertion `json:"assertions" yaml:"assertions"`
+	// Error expects evaluation to fail with an error containing it.
+	Error     string   `json:"error" yaml:"error"`
+	Tolerance *float64 `json:"tolerance" yaml:"tolerance"`
+}
+
+type Assertion struct {
+	Path      string   `json:"path" yaml:"path"`
+	Equals    any      `json:"equals" yaml:"equals"`
+	Exists    *bool    `json:"exists" yaml:"exists"`
+	Tolerance *float64 `json:"tolerance" yaml:"tolerance"`
+}
+
+// Discover finds test files in dir and its subdirectories, suites are sorted by key.
+func Discover(dir string) ([]Suite, error) {
+	var suites []Suite
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		suffix := testFileSuffix(entry.Name())
+		if suffix == "" {
+			return nil
+		}
+
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		suite, err := ReadSuite(path)
+		if err != nil {
+			return err
+		}
+
+		suite.Key = filepath.ToSlash(strings.TrimSuffix(relative, suffix) + ".json")
+		suites = append(suites, suite)
+		return nil
+	})
+
+	sort.Slice(suites, func(i, j int) bool {
+		return suites[i].Key < suites[j].Key
+	})
+
+	return suites, err
+}
+
+func testFileSuffix(name string) string {
+	for _, suffix := range testFileSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return suffix
+		}
+	}
+
+	return ""
+}
+
+// ReadSuite reads a JSON or YAML test file, Key is left empty.
+func ReadSuite(path string) (Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Suite{}, err
+	}
+
+	suite := Suite{Path: path}
+	if strings.HasSuffix(path, ".json") {
+		err = json.Unmarshal(data, &suite)
+	} else {
+		err = yaml.Unmarshal(data, &suite)
+	}
+
+	if err != nil {
+		return Suite{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i := range suite.Cases {
+		if suite.Cases[i].Name == "" {
+			suite.Cases[i].Name = strconv.Itoa(i + 1)
+		}
+	}
+
+	return suite, nil
+}
+
+// Run discovers test files in dir and runs each suite and case as subtests of t.
+func Run(t *testing.T, engine zen.Engine, dir string) {
+	t.Helper()
+
+	suites, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, suite := range suites {
+		RunSuite(t, engine, suite)
+	}
+}
+
+func RunSuite(t *testing.T, engine zen.Engine, suite Suite) {
+	t.Helper()
+
+	t.Run(suite.Key, func(t *testing.T) {
+		for _, c := range suite.Cases {
+			c := c
+			t.Run(c.Name, func(t *testing.T) {
+				for _, failure := range suite.Check(engine, c) {
+					t.Error(failure)
+				}
+			})
+		}
+	})
+}
+
+// Check evaluates c using the decision of the suite and returns a message for every failed expectation.
+func (suite Suite) Check(engine zen.Engine, c Case) []string {
+	tolerance := suite.Tolerance
+	if c.Tolerance != nil {
+		tolerance = *c.Tolerance
+	}
+
+	response, err := engine.Evaluate(suite.Key, c.Input)
+	if c.Error != "" {
+		switch {
+		case err == nil:
+			return []string{fmt.Sprintf("expected error containing %q, evaluation succeeded", c.Error)}
+		case !strings.Contains(err.Error(), c.Error):
+			return []string{fmt.Sprintf("expected error containing %q, got %q", c.Error, err.Error())}
+		default:
+			return nil
+		}
+	}
+
+	if err != nil {
+		return []string{fmt.Sprintf("evaluation failed: %v", err)}
+	}
+
+	var failures []string
+	if c.Output != nil {
+		failures = append(failures, compare("output", c.Output, response.Result, tolerance)...)
+	}
+
+	for _, assertion := range c.Assertions {
+		failures = append(failures, assertion.check(response.Result, tolerance)...)
+	}
+
+	return failures
+}
+
+func (assertion Assertion) check(result []byte, tolerance float64) []string {
+	if assertion.Tolerance != nil {
+		tolerance = *assertion.Tolerance
+	}
+
+	value := gjson.GetBytes(result, assertion.Path)
+	if assertion.Exists != nil && value.Exists() != *assertion.Exists {
+		if *assertion.Exists {
+			return []string{fmt.Sprintf("%s: expected to exist", assertion.Path)}
+		}
+
+		return []string{fmt.Sprintf("%s: expected not to exist, got %s", assertion.Path, value.Raw)}
+	}
+
+	if assertion.Equals == nil {
+		return nil
+	}
+
+	if !value.Exists() {
+		return []string{fmt.Sprintf("%s: expected %s, field is missing", assertion.Path, encode(assertion.Equals))}
+	}
+
+	return compare(assertion.Path, assertion.Equals, []byte(value.Raw), tolerance)
+}
+
+// compare diffs expected against actual, ignoring numbers which differ by at most tolerance.
+func compare(name string, expected any, actual []byte, tolerance float64) []string {
+	diffs, err := zen.DiffJSON(encode(expected), actual)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", name, err)}
+	}
+
+	var failures []string
+	for _, diff := range diffs {
+		if withinTolerance(diff, tolerance) {
+			continue
+		}
+
+		path := name
+		if diff.Path != "" {
+			path += "." + diff.Path
+		}
+
+		failures = append(failures, fmt.Sprintf("%s: expected %s, got %s", path, orMissing(diff.Expected), orMissing(diff.Actual)))
+	}
+
+	return failures
+}
+
+func withinTolerance(diff zen.FieldDiff, tolerance float64) bool {
+	if tolerance <= 0 {
+		return false
+	}
+
+	expected, err := strconv.ParseFloat(string(diff.Expected), 64)
+	if err != nil {
+		return false
+	}
+
+	actual, err := strconv.ParseFloat(string(diff.Actual), 64)
+	if err != nil {
+		return false
+	}
+
+	return math.Abs(expected-actual) <= tolerance
+}
+
+func orMissing(value json.RawMessage) string {
+	if value == nil {
+		return "<missing>"
+	}
+
+	return string(value)
+}
+
+func encode(value any) []byte {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return []byte(fmt.Sprintf("%q", fmt.Sprint(value)))
+	}
+
+	return data
+}
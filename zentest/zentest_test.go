@@ -0,0 +1,94 @@
+package zentest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gorules/zen-go"
+	"github.com/gorules/zen-go/zentest"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEngine returns fixed results by key, so expectations can be checked without evaluating decisions.
+type fakeEngine struct {
+	zen.Engine
+	results map[string]string
+}
+
+func (engine fakeEngine) Evaluate(key string, context any) (*zen.EvaluationResponse, error) {
+	return engine.EvaluateWithOpts(key, context, zen.EvaluationOptions{})
+}
+
+func (engine fakeEngine) EvaluateWithOpts(key string, context any, options zen.EvaluationOptions) (*zen.EvaluationResponse, error) {
+	result, ok := engine.results[key]
+	if !ok {
+		return nil, errors.New("age is required")
+	}
+
+	response := &zen.EvaluationResponse{Result: json.RawMessage(result)}
+	if options.Trace {
+		trace := json.RawMessage(`{"n1": {"id": "n1", "name": "Pricing", "performance": "12µs", "output": {"performance": "kept"}}}`)
+		response.Trace = &trace
+	}
+
+	return response, nil
+}
+
+func TestDiscover(t *testing.T) {
+	suites, err := zentest.Discover("testdata")
+	assert.NoError(t, err)
+	assert.Len(t, suites, 2)
+
+	assert.Equal(t, "nested/eligibility.json", suites[0].Key)
+	assert.Equal(t, "rejected", suites[0].Cases[0].Name)
+	assert.Equal(t, "2", suites[0].Cases[1].Name)
+	assert.Equal(t, map[string]any{"age": 12}, suites[0].Cases[0].Input)
+
+	assert.Equal(t, "pricing.json", suites[1].Key)
+	assert.Equal(t, 0.01, suites[1].Tolerance)
+	assert.Len(t, suites[1].Cases, 2)
+}
+
+func TestSuite_Check(t *testing.T) {
+	suites, err := zentest.Discover("testdata")
+	assert.NoError(t, err)
+	eligibility, pricing := suites[0], suites[1]
+
+	engine := fakeEngine{results: map[string]string{
+		"pricing.json":            `{"rate": 0.1001, "tier": "standard"}`,
+		"nested/eligibility.json": `{"eligible": false, "reason": "too young"}`,
+	}}
+	for _, c := range pricing.Cases {
+		assert.Empty(t, pricing.Check(engine, c), c.Name)
+	}
+
+	assert.Empty(t, eligibility.Check(engine, eligibility.Cases[0]))
+
+	engine.results["pricing.json"] = `{"rate": 0.2, "tier": "gold", "reason": "vip"}`
+	assert.Equal(t, []string{
+		"output.rate: expected 0.1, got 0.2",
+		"output.reason: expected <missing>, got \"vip\"",
+		"output.tier: expected \"standard\", got \"gold\"",
+	}, pricing.Check(engine, pricing.Cases[0]))
+	assert.Equal(t, []string{
+		"rate: expected 0.105, got 0.2",
+		"reason: expected not to exist, got \"vip\"",
+	}, pricing.Check(engine, pricing.Cases[1]))
+
+	// eligibility.json has no result in the fake engine, so evaluation fails as expected
+	delete(engine.results, "nested/eligibility.json")
+	assert.Empty(t, eligibility.Check(engine, eligibility.Cases[1]))
+	assert.Equal(t, []string{"evaluation failed: age is required"}, eligibility.Check(engine, eligibility.Cases[0]))
+}
+
+func TestRun(t *testing.T) {
+	engine := zen.NewEngine(zen.EngineConfig{Loader: func(key string) ([]byte, error) {
+		return os.ReadFile(path.Join("..", "test-data", key))
+	}})
+	defer engine.Dispose()
+
+	zentest.Run(t, engine, path.Join("..", "test-data"))
+}